@@ -0,0 +1,93 @@
+package main
+
+import (
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "strings"
+    "text/tabwriter"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+)
+
+func compareCommand(argv []string) error {
+    flags := flag.NewFlagSet("compare", flag.ExitOnError)
+    tolerance := flags.Float64("tolerance", 0.1, "relative change of the median a significant difference must exceed to count as a regression")
+    alpha := flags.Float64("alpha", 0.05, "significance level of the Mann-Whitney U test")
+    jsonOut := flags.String("json", "", "also write the comparison as JSON to this file, - for stdout")
+    flags.Parse(argv)
+
+    if flags.NArg() < 2 {
+        return errors.New("Usage: collector compare [flags] <baseline-dir> <run-dir>...")
+    }
+
+    baseline, err := analysis.LoadRun(flags.Arg(0))
+    if err != nil {
+        return err
+    }
+
+    var comparisons []analysis.Comparison
+    regressions := 0
+    for _, dir := range flags.Args()[1:] {
+        candidate, err := analysis.LoadRun(dir)
+        if err != nil {
+            return err
+        }
+        c := analysis.Compare(baseline, candidate, *tolerance, *alpha)
+        comparisons = append(comparisons, c)
+        regressions += c.Regressions()
+
+        if *jsonOut != "-" {
+            printComparison(c, flags.Arg(0), dir)
+        }
+    }
+
+    if *jsonOut != "" {
+        data, err := json.MarshalIndent(comparisons, "", "  ")
+        if err != nil {
+            return err
+        }
+        data = append(data, '\n')
+        if *jsonOut == "-" {
+            os.Stdout.Write(data)
+        } else if err := os.WriteFile(*jsonOut, data, 0644); err != nil {
+            return err
+        }
+    }
+
+    if regressions > 0 {
+        return fmt.Errorf("%d significant regressions", regressions)
+    }
+    return nil
+}
+
+// printComparison prints one candidate against the baseline, a row per stage
+// with every statistic as baseline → candidate and its relative change.
+func printComparison(c analysis.Comparison, baseDir, dir string) {
+    fmt.Printf("\n ====== %s (%s) vs baseline %s (%s) ======\n", dir, c.Candidate.ID, baseDir, c.Baseline.ID)
+    tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+    header := []string{"STAGE", "N"}
+    if len(c.Stages) > 0 {
+        for _, d := range c.Stages[0].Deltas {
+            header = append(header, strings.ToUpper(d.Metric))
+        }
+    }
+    fmt.Fprintln(tw, strings.Join(append(header, "P-VALUE", "VERDICT"), "\t"))
+
+    for _, s := range c.Stages {
+        row := []string{s.Stage, fmt.Sprintf("%d/%d", s.Baseline, s.Candidate)}
+        for _, d := range s.Deltas {
+            row = append(row, fmt.Sprintf("%v → %v (%+.0f%%)", d.Baseline.Round(time.Millisecond), d.Candidate.Round(time.Millisecond), d.Change*100))
+        }
+        verdict := s.Verdict
+        if verdict == analysis.Regression {
+            verdict = "❌ " + verdict
+        }
+        fmt.Fprintln(tw, strings.Join(append(row, fmt.Sprintf("%.3g", s.PValue), verdict), "\t"))
+    }
+    tw.Flush()
+}
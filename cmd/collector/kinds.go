@@ -0,0 +1,33 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+    "github.com/asmit27rai/collector/pkg/report"
+)
+
+// reportKinds writes the per-kind downsync comparison of result to
+// kinds.json and kinds.txt and prints it.
+func reportKinds(outputDir string, result *analysis.Result) error {
+    kinds := result.Kinds
+    if kinds == nil {
+        kinds = []analysis.KindSummary{}
+    }
+    data, err := json.MarshalIndent(kinds, "", "  ")
+    if err != nil {
+        return err
+    }
+    if err := os.WriteFile(filepath.Join(outputDir, "kinds.json"), append(data, '\n'), 0644); err != nil {
+        return err
+    }
+    if err := report.WriteKinds(filepath.Join(outputDir, "kinds.txt"), kinds); err != nil {
+        return err
+    }
+
+    fmt.Println()
+    return report.Kinds(os.Stdout, kinds)
+}
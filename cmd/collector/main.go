@@ -1,55 +1,193 @@
 package main
 
 import (
-    "encoding/csv"
+    "context"
+    "errors"
+    "flag"
     "fmt"
     "log"
+    "net/http"
     "os"
-    "os/exec"
+    "os/signal"
     "strings"
+    "syscall"
     "time"
     "strconv"
 	"path/filepath"
 
+    "github.com/asmit27rai/collector/pkg/analysis"
     "github.com/asmit27rai/collector/pkg/collector"
+    "github.com/asmit27rai/collector/pkg/config"
+    "github.com/asmit27rai/collector/pkg/export"
+    "github.com/asmit27rai/collector/pkg/report"
+    "github.com/asmit27rai/collector/pkg/store"
     "github.com/asmit27rai/collector/pkg/writer"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
-// LatencyData holds timestamps for calculations
-type LatencyData struct {
-    WDSDeployCreate    time.Time
-    WDSDeployStatus    time.Time
-    WECDeployCreate    time.Time
-    WECDeployStatus    time.Time
-    BindingCreate      time.Time
-    ManifestWorkCreate time.Time
-    AppliedManifestCreate time.Time
-    WorkStatusUpdate   time.Time
-}
+// errInterrupted is returned when collection stops on SIGINT/SIGTERM.
+var errInterrupted = errors.New("collection interrupted")
+
+// errIncomplete is returned when some collection tasks failed; the results
+// that were collected are still written out.
+var errIncomplete = errors.New("collection incomplete")
+
+// errObjectives is returned when a run violates a latency objective of the
+// experiment config.
+var errObjectives = errors.New("latency objectives violated")
 
-func collectLongExperiment(wds, its, wec *collector.Collector, args collector.CollectionArgs) error {
+func collectLongExperiment(ctx context.Context, wds, its, wec *collector.Collector, args collector.CollectionArgs) error {
     // Implement long-running experiment collection
     log.Println("Long experiment collection not implemented yet")
     return nil
 }
 
+// commands are the subcommands available besides the default collection run.
+var commands = map[string]func(args []string) error{
+    "preflight": preflightCommand,
+    "rbac":      rbacCommand,
+    "query":     queryCommand,
+    "compare":   compareCommand,
+}
+
 func main() {
-    if len(os.Args) < 7 {
-        log.Fatal("Usage: collector <kubeconfig> <wds-context> <its-context> <wec-context> <num-ns> <output-dir> [exp-type]")
+    if len(os.Args) > 1 {
+        if command, ok := commands[os.Args[1]]; ok {
+            if err := command(os.Args[2:]); err != nil {
+                log.Fatal(err)
+            }
+            return
+        }
     }
 
-    args := parseArgs(os.Args[1:])
-    if err := runCollection(args); err != nil {
+    flags := flag.NewFlagSet("collector", flag.ExitOnError)
+    client := addClientFlags(flags)
+    clock := addClockFlags(flags)
+    workers := flags.Int("workers", 8, "number of concurrent collection workers")
+    formats := flags.String("format", "tsv", "comma-separated output formats: "+strings.Join(writer.Formats, ", "))
+    sqlitePath := flags.String("sqlite", "", "also store the run in this SQLite database, shared across runs")
+    parquetOut := flags.Bool("parquet", false, "also write latencies.parquet and stage_timestamps.parquet to the output directory")
+    openMetrics := flags.String("openmetrics", "", "write latency histograms in OpenMetrics text format to this file, e.g. for node_exporter's textfile collector")
+    pushgateway := flags.String("pushgateway", "", "push latency histograms to this Pushgateway URL")
+    pushJob := flags.String("push-job", "kubestellar_collector", "job name to group pushed metrics under")
+    otlpFile := flags.String("otlp-file", "", "write object lifecycles as OTLP/JSON traces to this file")
+    configPath := flags.String("config", "", "experiment config file (YAML) with latency objectives, repeated runs and parameter sweeps")
+    markdown := flags.String("markdown", "", "write a Markdown summary, e.g. for a pull request comment, to this file")
+    junit := flags.String("junit", "", "write the stage checks as a JUnit XML report to this file")
+    htmlReport := flags.String("html", "", "write a self-contained HTML report with charts to this file")
+    chromeTrace := flags.String("chrome-trace", "", "write a timeline of every object's stages in Chrome Trace Event Format to this file, for Perfetto")
+    otlpEndpoint := flags.String("otlp-endpoint", "", "send object lifecycles as traces to this OTLP/HTTP endpoint, e.g. http://localhost:4318")
+    correctSkew := flags.Bool("correct-skew", false, "correct every timestamp for the clock offset of its cluster, as estimated with -clock-probes")
+    skipPreflight := flags.Bool("skip-preflight", false, "do not verify contexts, CRDs and RBAC before collecting")
+    flags.Parse(os.Args[1:])
+
+    if flags.NArg() < 6 {
+        log.Fatal("Usage: collector [flags] <kubeconfig> <wds-context> <its-context> <wec-context> <num-ns> <output-dir> [exp-type]\n" +
+            "       collector preflight [flags] <kubeconfig> <wds-context> <its-context> <wec-context> <num-ns>\n" +
+            "       collector rbac [flags] <wds-context> <its-context> <wec-context> <num-ns>\n" +
+            "       collector query <sqlite-db> <sql>\n" +
+            "       collector compare [flags] <baseline-dir> <run-dir>...")
+    }
+
+    args := parseArgs(flags.Args())
+    if err := client.apply(&args); err != nil {
         log.Fatal(err)
     }
+    clock.apply(&args)
+    args.CorrectSkew = *correctSkew
+    args.Workers = *workers
+    args.SkipPreflight = *skipPreflight
+    args.Formats = strings.Split(*formats, ",")
+    args.SQLitePath = *sqlitePath
+    args.Parquet = *parquetOut
+    args.OpenMetricsPath = *openMetrics
+    args.Pushgateway = *pushgateway
+    args.PushJob = *pushJob
+    args.OTLPFile = *otlpFile
+    args.OTLPEndpoint = *otlpEndpoint
+    args.ChromeTrace = *chromeTrace
+    args.HTMLReport = *htmlReport
+    args.Markdown = *markdown
+    args.JUnit = *junit
+    if args.CorrectSkew && args.ClockProbes < 1 {
+        log.Fatal("-correct-skew needs clock offsets; set -clock-probes")
+    }
+
+    exp := &config.Experiment{}
+    if *configPath != "" {
+        var err error
+        if exp, err = config.Load(*configPath); err != nil {
+            log.Fatal(err)
+        }
+    }
+
+    ctx, stop := signalContext()
+    defer stop()
+
+    err := runCollection(ctx, args, exp)
+    if errors.Is(err, errInterrupted) {
+        log.Printf("⚠️  Run interrupted; partial results in %s are marked incomplete", args.OutputDir)
+        os.Exit(130)
+    }
+    if errors.Is(err, errObjectives) {
+        log.Printf("❌ %v", err)
+        os.Exit(3)
+    }
+    if errors.Is(err, errIncomplete) {
+        log.Printf("⚠️  Data set is incomplete; failed collections are listed in %s/errors.json", args.OutputDir)
+        os.Exit(2)
+    }
+    if err != nil {
+        log.Fatal(err)
+    }
+}
+
+// clientFlags are the API client settings shared by every subcommand that
+// talks to the clusters.
+type clientFlags struct {
+    qps     *float64
+    burst   *int
+    timeout *time.Duration
+    retries *int
+}
+
+func addClientFlags(flags *flag.FlagSet) *clientFlags {
+    return &clientFlags{
+        qps:     flags.Float64("qps", 50, "client-side request rate limit per cluster"),
+        burst:   flags.Int("burst", 100, "client-side request burst per cluster"),
+        timeout: flags.Duration("timeout", 30*time.Second, "timeout for each API call attempt"),
+        retries: flags.Int("retries", 4, "retries for transient API errors (429, 5xx, connection resets)"),
+    }
+}
+
+func (f *clientFlags) apply(args *collector.CollectionArgs) error {
+    if *f.qps < 0 || *f.burst < 0 {
+        return fmt.Errorf("-qps and -burst must not be negative, got %v and %d", *f.qps, *f.burst)
+    }
+    args.QPS = float32(*f.qps)
+    args.Burst = *f.burst
+    args.Timeout = *f.timeout
+    args.Retries = *f.retries
+    return nil
+}
+
+// signalContext is cancelled on SIGINT/SIGTERM. A second signal falls
+// through to the default handler and kills the process if flushing partial
+// results hangs.
+func signalContext() (context.Context, context.CancelFunc) {
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    go func() {
+        <-ctx.Done()
+        stop()
+    }()
+    return ctx, stop
 }
 
 func parseArgs(args []string) collector.CollectionArgs {
     numNS, _ := strconv.Atoi(args[4])
     expType := "s"
-    if len(args) > 7 {
-        expType = args[7]
+    if len(args) > 6 {
+        expType = args[6]
     }
 
     // kubeconfig, err := filepath.EvalSymlinks(args[0])
@@ -68,404 +206,440 @@ func parseArgs(args []string) collector.CollectionArgs {
     }
 }
 
-func runCollection(args collector.CollectionArgs) error {
-    wdsCollector, err := collector.NewCollector(args.Kubeconfig, args.WDSContext)
-    if err != nil {
-        return err
+// dedupe drops repeated entries from s, keeping the first occurrence.
+func dedupe(s []string) []string {
+    seen := map[string]bool{}
+    var out []string
+    for _, v := range s {
+        v = strings.TrimSpace(v)
+        if v == "" || seen[v] {
+            continue
+        }
+        seen[v] = true
+        out = append(out, v)
     }
+    return out
+}
 
-    itsCollector, err := collector.NewCollector(args.Kubeconfig, args.ITSContext)
+// newCollectors builds one collector per cluster role.
+func newCollectors(args collector.CollectionArgs) (wds, its, wec *collector.Collector, err error) {
+    opts := collector.Options{
+        QPS:     args.QPS,
+        Burst:   args.Burst,
+        Timeout: args.Timeout,
+        Retries: args.Retries,
+    }
+
+    wds, err = collector.NewCollector(args.Kubeconfig, args.WDSContext, opts)
     if err != nil {
-        return err
+        return nil, nil, nil, err
     }
+    wds.Role = collector.RoleWDS
 
-    wecCollector, err := collector.NewCollector(args.Kubeconfig, args.WECContext)
+    its, err = collector.NewCollector(args.Kubeconfig, args.ITSContext, opts)
     if err != nil {
-        return err
+        return nil, nil, nil, err
     }
+    its.Role = collector.RoleITS
 
-    if args.ExpType == "s" {
-        return collectShortExperiment(wdsCollector, itsCollector, wecCollector, args)
+    wec, err = collector.NewCollector(args.Kubeconfig, args.WECContext, opts)
+    if err != nil {
+        return nil, nil, nil, err
     }
-    return collectLongExperiment(wdsCollector, itsCollector, wecCollector, args)
+    wec.Role = collector.RoleWEC
+    return wds, its, wec, nil
 }
 
-func collectShortExperiment(wds, its, wec *collector.Collector, args collector.CollectionArgs) error {
-    objKinds := []string{"deployments", "secrets", "configmaps", "services"}
-    
-    for ns := 0; ns < args.NumNS; ns++ {
-        nsName := fmt.Sprintf("perf-test-%d", ns)
-        nsPath := filepath.Join(args.OutputDir, nsName)
-        
-        // Collect standard resources
-        for _, kind := range objKinds {
-            // WDS metrics
-            wdsMetrics, err := wds.CollectStandardObjects(kind, nsName)
-            if err != nil {
-                return err
-            }
-            writer.WriteMetrics(nsPath, kind, "wds", wdsMetrics)
+func runCollection(ctx context.Context, args collector.CollectionArgs, exp *config.Experiment) error {
+    wdsCollector, itsCollector, wecCollector, err := newCollectors(args)
+    if err != nil {
+        return err
+    }
 
-            // WEC metrics
-            wecMetrics, err := wec.CollectStandardObjects(kind, nsName)
-            if err != nil {
-                return err
+    if !args.SkipPreflight {
+        if !runPreflight(ctx, args, wdsCollector, itsCollector, wecCollector) {
+            if ctx.Err() != nil {
+                return ctx.Err()
             }
-            writer.WriteMetrics(nsPath, kind, "wec", wecMetrics)
+            return errors.New("preflight checks failed; fix the problems above or rerun with -skip-preflight")
         }
+    }
 
-        if err := collectCustomResources(its, wec, args, nsName, nsPath); err != nil {
-            return err
-        }
-        
-        // Collect custom resources
-        if err := collectCustomResources(its, wec, args, nsName, nsPath); err != nil {
-            return err
-        }
+    if exp.Sweep != nil {
+        return runSweep(ctx, wdsCollector, itsCollector, wecCollector, args, exp)
     }
-    latencyData, err := gatherLatencyData(args.OutputDir, args.WDSContext)
-    if err != nil {
-        return fmt.Errorf("error gathering latency data: %v", err)
+    if exp.Repeat != nil {
+        _, err := runRepeated(ctx, wdsCollector, itsCollector, wecCollector, args, exp, nil)
+        return err
     }
-
-    // Write to file instead of terminal
-    if err := writeLatenciesToFile(latencyData, args.OutputDir); err != nil {
-        return fmt.Errorf("error writing results: %v", err)
+    if args.ExpType == "s" {
+        return collectShortExperiment(ctx, wdsCollector, itsCollector, wecCollector, args, exp)
     }
-
-    calculateAndPrintLatencies(latencyData)
-    
-    log.Printf("✅ Metrics written to: %s/latency_results.txt", args.OutputDir)
-    return nil
+    return collectLongExperiment(ctx, wdsCollector, itsCollector, wecCollector, args)
 }
 
-func collectCustomResources(its *collector.Collector, wec *collector.Collector, args collector.CollectionArgs, nsName string, nsPath string) error {
-    bindingPolicy := nsName
-    labelSelector := fmt.Sprintf("transport.kubestellar.io/originOwnerReferenceBindingKey=%s", bindingPolicy)
-
-    // Collect ManifestWorks
-    manifestGVR := schema.GroupVersionResource{
-        Group:    "work.open-cluster-management.io",
-        Version:  "v1",
-        Resource: "manifestworks",
-    }
-    manifestMetrics, err := its.CollectCustomResources(
-        manifestGVR, 
-        args.WECContext, // Use WEC context as namespace
-        labelSelector,
-    )
-    if err != nil {
-        return err
-    }
+func collectShortExperiment(ctx context.Context, wds, its, wec *collector.Collector, args collector.CollectionArgs, exp *config.Experiment) error {
+    // A marker left over from an earlier interrupted run into the same
+    // directory no longer applies.
+    os.Remove(filepath.Join(args.OutputDir, "INCOMPLETE"))
 
-    // Collect WorkStatuses
-    statusGVR := schema.GroupVersionResource{
-        Group:    "control.kubestellar.io",
-        Version:  "v1alpha1",
-        Resource: "workstatuses",
-    }
-    statusMetrics, err := its.CollectCustomResources(
-        statusGVR,
-        args.WECContext, // Use WEC context as namespace
-        labelSelector,
-    )
+    sink, err := writer.NewSink(args.OutputDir, dedupe(args.Formats))
     if err != nil {
         return err
     }
+    defer sink.Close()
 
-    // Collect AppliedManifestWork
-    appliedGVR := schema.GroupVersionResource{
-        Group:    "work.open-cluster-management.io",
-        Version:  "v1",
-        Resource: "appliedmanifestworks",
+    run := analysis.NewRun(args, time.Now())
+    var clockWarnings []string
+    if args.ClockProbes > 0 {
+        run.Clocks, clockWarnings = probeClocks(ctx, args, wds, its, wec)
+        if missing := analysis.MissingClocks(run.Clocks); args.CorrectSkew && len(missing) > 0 {
+            roles := make([]string, len(missing))
+            for i, r := range missing {
+                roles[i] = string(r)
+            }
+            warning := fmt.Sprintf("timestamps were not corrected for clock skew: no clock offset for %s", strings.Join(roles, ", "))
+            log.Printf("⚠️  %s", warning)
+            clockWarnings = append(clockWarnings, warning)
+        } else {
+            run.SkewCorrected = args.CorrectSkew
+        }
     }
-    appliedMetrics, err := wec.CollectCustomResources(
-        appliedGVR,
-        "",
-        "",
-    )
-    if err != nil {
-        fmt.Printf("Error collecting applied manifest works: %v\n", err)
-        return err
+    dataset := &collector.Dataset{}
+    tasks := []collector.Task{
+        // AppliedManifestWorks are cluster-scoped, so they are listed once
+        // rather than per namespace.
+        {
+            Cluster: wec.Context,
+            GVR:     collector.AppliedManifestWorkGVR,
+            Run: func(ctx context.Context) error {
+                metrics, err := wec.CollectCustomResources(ctx, collector.AppliedManifestWorkGVR, "", "")
+                if err != nil {
+                    return err
+                }
+                dataset.AddWorks(metrics)
+                return sink.Write(writer.WorkTable("", collector.AppliedManifestWorkGVR.Resource, metrics))
+            },
+        },
     }
+    for ns := 0; ns < args.NumNS; ns++ {
+        nsName := collector.ExperimentNamespace(ns)
 
-    // Write results
-    writer.WriteWorkMetrics(nsPath, "manifestworks", manifestMetrics)
-    writer.WriteWorkMetrics(nsPath, "workstatuses", statusMetrics)
-    writer.WriteWorkMetrics(nsPath, "appliedmanifestworks", appliedMetrics)
+        // Collect standard resources
+        for _, kind := range collector.StandardKinds {
+            for _, c := range []*collector.Collector{wds, wec} {
+                kind, c := kind, c
+                gvr, _ := collector.StandardGVR(kind)
+                tasks = append(tasks, collector.Task{
+                    Cluster:   c.Context,
+                    GVR:       gvr,
+                    Namespace: nsName,
+                    Run: func(ctx context.Context) error {
+                        metrics, err := c.CollectStandardObjects(ctx, kind, nsName)
+                        if err != nil {
+                            return err
+                        }
+                        dataset.AddObjects(metrics)
+                        return sink.Write(writer.ObjectTable(nsName, kind, c.Role, metrics))
+                    },
+                })
+            }
+        }
 
-    return nil
-}
+        // Collect custom resources
+        tasks = append(tasks, customResourceTasks(its, args, nsName, sink, dataset)...)
+    }
+
+    // The binding policy is fetched like any list: if it is missing, the
+    // stages that start at it go unmeasured but everything else is kept.
+    var bindingCreated time.Time
+    tasks = append(tasks, collector.Task{
+        Cluster: wds.Context,
+        GVR:     collector.BindingPolicyGVR,
+        Run: func(ctx context.Context) error {
+            var err error
+            bindingCreated, err = getBindingCreationTime(ctx, wds)
+            return err
+        },
+    })
 
-func gatherLatencyData(outputDir, wdsContext string) (*LatencyData, error) {
-    data := &LatencyData{}
-    var err error
+    start := time.Now()
+    scheduler := collector.Scheduler{Workers: args.Workers}
+    completed, failures := scheduler.Run(ctx, tasks)
 
-    log.Println("Gathering latency data...")
-    
-    // Get binding policy creation time
-    data.BindingCreate, err = getBindingCreationTime(wdsContext)
-    if err != nil {
-        return nil, fmt.Errorf("failed to get binding creation time: %v", err)
+    // Every finished task has already written its file; record what is
+    // missing so nobody mistakes the directory for a full run.
+    if err := writer.WriteErrors(args.OutputDir, failures); err != nil {
+        log.Printf("error writing errors.json: %v", err)
+    }
+    if err := writeThrottleStats(args.OutputDir, wds, its, wec); err != nil {
+        log.Printf("error writing throttle stats: %v", err)
     }
-    log.Printf("Binding created at: %v", data.BindingCreate)
+    if ctx.Err() != nil {
+        reason := fmt.Sprintf("interrupted after %d of %d collection tasks", completed, len(tasks))
+        if err := writeIncompleteMarker(args.OutputDir, reason); err != nil {
+            log.Printf("error writing incomplete marker: %v", err)
+        }
+        return errInterrupted
+    }
+    log.Printf("Collected %d of %d lists across %d namespaces in %v", completed, len(tasks), args.NumNS, time.Since(start).Round(time.Millisecond))
 
-    // Read WDS deployment timestamps
-    wdsPath := filepath.Join(outputDir, "perf-test-0/deployments-wds/deployments.csv")
-    log.Printf("Reading WDS deployments from: %s", wdsPath)
-    data.WDSDeployCreate, data.WDSDeployStatus, err = readDeploymentTimestamps(wdsPath)
-    if err != nil {
-        return nil, fmt.Errorf("error reading WDS deployments: %v", err)
+    if len(failures) > 0 {
+        for _, f := range failures {
+            log.Printf("❌ %s %s in %q: %s", f.Cluster, f.GVR, f.Namespace, f.Error)
+        }
+        reason := fmt.Sprintf("%d of %d collection tasks failed, see errors.json", len(failures), len(tasks))
+        if err := writeIncompleteMarker(args.OutputDir, reason); err != nil {
+            log.Printf("error writing incomplete marker: %v", err)
+        }
     }
 
-    // Read WEC deployment timestamps
-    wecPath := filepath.Join(outputDir, "perf-test-0/deployments-wec/deployments.csv")
-    log.Printf("Reading WEC deployments from: %s", wecPath)
-    data.WECDeployCreate, data.WECDeployStatus, err = readDeploymentTimestamps(wecPath)
-    if err != nil {
-        return nil, fmt.Errorf("error reading WEC deployments: %v", err)
+    if !bindingCreated.IsZero() {
+        log.Printf("Binding created at: %v", bindingCreated)
     }
 
-    // Read ManifestWork timestamps
-    mwPath := filepath.Join(outputDir, "perf-test-0/manifestworks/manifestworks.csv")
-    log.Printf("Reading ManifestWorks from: %s", mwPath)
-    data.ManifestWorkCreate, err = readCSVTimestamp(mwPath, 1)
-    if err != nil {
-        return nil, fmt.Errorf("error reading ManifestWorks: %v", err)
+    run.Finished = time.Now()
+    run.Complete = len(failures) == 0
+    result := analysis.Analyze(run, dataset, bindingCreated, exp.StageSet())
+    result.Warnings = append(result.Warnings, clockWarnings...)
+    for _, f := range failures {
+        result.Warnings = append(result.Warnings, fmt.Sprintf("could not collect %s in %q on %s: %s", f.GVR, f.Namespace, f.Cluster, f.Error))
+    }
+    if err := analysis.WriteRunFile(args.OutputDir, result); err != nil {
+        return fmt.Errorf("error writing %s: %v", analysis.RunFile, err)
+    }
+    if err := writeThroughput(args.OutputDir, result); err != nil {
+        return fmt.Errorf("error writing throughput: %v", err)
+    }
+    slo := analysis.Evaluate(result, exp.Objectives)
+    checks := append(analysis.StageChecks(result), slo...)
+    if err := exportResult(ctx, result, checks, args); err != nil {
+        if ctx.Err() != nil {
+            if err := writeIncompleteMarker(args.OutputDir, "interrupted while exporting results"); err != nil {
+                log.Printf("error writing incomplete marker: %v", err)
+            }
+            return errInterrupted
+        }
+        return fmt.Errorf("error exporting results: %v", err)
     }
 
-    // Read AppliedManifestWork timestamps
-    amwPath := filepath.Join(outputDir, "perf-test-0/appliedmanifestworks/appliedmanifestworks.csv")
-    log.Printf("Reading AppliedManifestWorks from: %s", amwPath)
-    data.AppliedManifestCreate, err = readCSVTimestamp(amwPath, 1)
+    lc, err := sampleLifecycle(result)
     if err != nil {
-        return nil, fmt.Errorf("error reading AppliedManifestWorks: %v", err)
+        if len(failures) > 0 {
+            log.Printf("error gathering latency data: %v", err)
+            return errIncomplete
+        }
+        return fmt.Errorf("error gathering latency data: %v", err)
     }
+    log.Printf("Using deployment %s/%s", lc.Namespace, lc.Name)
 
-    // Handle WorkStatus with fallback
-    wsPath := filepath.Join(outputDir, "perf-test-0/workstatuses/workstatuses.csv")
-    log.Printf("Reading WorkStatuses from: %s", wsPath)
-    data.WorkStatusUpdate, err = readCSVTimestamp(wsPath, 1)
-    if err != nil {
-        log.Printf("WorkStatus update time unavailable: %v", err)
-        log.Println("   This is normal if status hasn't been reported yet")
-        data.WorkStatusUpdate = time.Time{} // Explicit zero time
+    if err := report.WriteText(filepath.Join(args.OutputDir, "latency_results.txt"), result, *lc); err != nil {
+        return fmt.Errorf("error writing results: %v", err)
+    }
+    fmt.Println()
+    report.Text(os.Stdout, result, *lc)
+    if err := reportKinds(args.OutputDir, result); err != nil {
+        return fmt.Errorf("error writing per-kind results: %v", err)
+    }
+    if err := reportOutliers(args.OutputDir, result, exp.OutlierRule()); err != nil {
+        return fmt.Errorf("error writing outliers: %v", err)
     }
 
-    log.Println("All latency data collected successfully")
-    return data, nil
-}
+    log.Printf("✅ Metrics written to: %s/latency_results.txt", args.OutputDir)
 
-func getBindingCreationTime(context string) (time.Time, error) {
-    // Get actual binding policy resource (not CRD)
-    cmd := exec.Command("kubectl", "--context", context, "get", "bindingpolicies.control.kubestellar.io", 
-        "nginx-bpolicy", "-o", "jsonpath={.metadata.creationTimestamp}")
-    
-    output, err := cmd.Output()
-    if err != nil {
-        return time.Time{}, fmt.Errorf("error getting binding policy: %v\nDid you create the binding policy after the deployment?", err)
+    if len(slo) > 0 && !printChecks(slo) {
+        return errObjectives
+    }
+    if len(failures) > 0 {
+        return errIncomplete
     }
+    return nil
+}
 
-    return time.Parse(time.RFC3339, strings.TrimSpace(string(output)))
+// writeThroughput writes the throughput and backlog series of result to
+// the output directory and logs how far the backlogs peaked.
+func writeThroughput(outputDir string, result *analysis.Result) error {
+    tp := analysis.ComputeThroughput(result.AllLifecycles)
+    if err := analysis.WriteThroughput(outputDir, tp); err != nil {
+        return err
+    }
+    for _, s := range tp.Series {
+        if s.Unit != analysis.UnitBacklog {
+            continue
+        }
+        peak, at := s.Peak()
+        log.Printf("Peak backlog %s: %.0f objects, %v after the first event", s.Name, peak, time.Duration(at+1)*tp.Interval)
+    }
+    log.Printf("Throughput written to %s/throughput.csv", outputDir)
+    return nil
 }
 
-func readDeploymentTimestamps(path string) (time.Time, time.Time, error) {
-    file, err := os.Open(path)
+// writeThrottleStats records client-side rate limiter waits per cluster so
+// they can be told apart from API server latency.
+func writeThrottleStats(outputDir string, collectors ...*collector.Collector) error {
+    if err := os.MkdirAll(outputDir, 0755); err != nil {
+        return err
+    }
+    f, err := os.Create(filepath.Join(outputDir, "throttling.txt"))
     if err != nil {
-        return time.Time{}, time.Time{}, err
+        return err
     }
-    defer file.Close()
-
-    reader := csv.NewReader(file)
-    reader.Comma = '\t'
-    reader.FieldsPerRecord = -1 // Allow variable fields
-    reader.LazyQuotes = true
+    defer f.Close()
 
-    // Skip header
-    if _, err := reader.Read(); err != nil {
-        return time.Time{}, time.Time{}, err
+    if _, err := f.WriteString("Context\tRequests\tThrottled\tWaited\n"); err != nil {
+        return err
     }
-
-    records, err := reader.ReadAll()
-    if err != nil {
-        return time.Time{}, time.Time{}, err
+    for _, c := range collectors {
+        stats := c.ThrottleStats()
+        if stats.Throttled > 0 {
+            log.Printf("%s: %d of %d requests throttled client-side, %v spent waiting",
+                c.Context, stats.Throttled, stats.Requests, stats.Waited.Round(time.Millisecond))
+        }
+        line := fmt.Sprintf("%s\t%d\t%d\t%s\n", c.Context, stats.Requests, stats.Throttled, stats.Waited)
+        if _, err := f.WriteString(line); err != nil {
+            return err
+        }
     }
+    return nil
+}
 
-    if len(records) < 1 {
-        return time.Time{}, time.Time{}, fmt.Errorf("no data rows in %s", path)
+func customResourceTasks(its *collector.Collector, args collector.CollectionArgs, nsName string, sink writer.Sink, dataset *collector.Dataset) []collector.Task {
+    bindingPolicy := nsName
+    labelSelector := fmt.Sprintf("%s=%s", collector.BindingKeyLabel, bindingPolicy)
+
+    task := func(c *collector.Collector, gvr schema.GroupVersionResource, namespace, selector string) collector.Task {
+        return collector.Task{
+            Cluster:   c.Context,
+            GVR:       gvr,
+            Namespace: namespace,
+            Run: func(ctx context.Context) error {
+                metrics, err := c.CollectCustomResources(ctx, gvr, namespace, selector)
+                if err != nil {
+                    return err
+                }
+                dataset.AddWorks(metrics)
+                return sink.Write(writer.WorkTable(nsName, gvr.Resource, metrics))
+            },
+        }
     }
 
-    dataRow := records[0] // First data row after header
-    if len(dataRow) < 4 {
-        return time.Time{}, time.Time{}, fmt.Errorf("invalid data format in %s", path)
+    return []collector.Task{
+        task(its, collector.ManifestWorkGVR, args.WECContext, labelSelector), // Use WEC context as namespace
+        task(its, collector.WorkStatusGVR, args.WECContext, labelSelector),
     }
+}
 
-    creationTime, err := time.Parse(time.RFC3339, dataRow[1])
-    if err != nil {
-        return time.Time{}, time.Time{}, err
+// sampleLifecycle picks the first deployment in the first experiment
+// namespace for latency_results.txt and the console report.
+func sampleLifecycle(result *analysis.Result) (*analysis.Lifecycle, error) {
+    nsName := collector.ExperimentNamespace(0)
+    for i := range result.Lifecycles {
+        if result.Lifecycles[i].Namespace == nsName && result.Lifecycles[i].Kind == "deployments" {
+            return &result.Lifecycles[i], nil
+        }
     }
+    return nil, fmt.Errorf("no deployments in %s on the WDS", nsName)
+}
 
-    statusTime, err := time.Parse(time.RFC3339, dataRow[2])
-    if err != nil {
-        return time.Time{}, time.Time{}, err
+// exportResult writes the analysed run to every store and exporter enabled
+// in args.
+func exportResult(ctx context.Context, result *analysis.Result, checks []analysis.Check, args collector.CollectionArgs) error {
+    if args.Parquet {
+        if err := store.WriteParquet(args.OutputDir, result); err != nil {
+            return err
+        }
+        log.Printf("Parquet files written to %s", args.OutputDir)
     }
 
-    return creationTime, statusTime, nil
-}
+    if args.SQLitePath != "" {
+        db, err := store.OpenSQLite(args.SQLitePath)
+        if err != nil {
+            return err
+        }
+        defer db.Close()
 
-func readCSVTimestamp(path string, timeColumn int) (time.Time, error) {
-    file, err := os.Open(path)
-    if err != nil {
-        return time.Time{}, fmt.Errorf("failed to open file: %v", err)
+        if err := db.WriteResult(result); err != nil {
+            return err
+        }
+        log.Printf("Run %s stored in %s", result.Run.ID, args.SQLitePath)
     }
-    defer file.Close()
 
-    reader := csv.NewReader(file)
-    reader.Comma = '\t'
-    reader.FieldsPerRecord = -1
-    reader.LazyQuotes = true
+    if args.OpenMetricsPath != "" {
+        if err := export.WriteOpenMetrics(args.OpenMetricsPath, result, result.Stages); err != nil {
+            return err
+        }
+        log.Printf("OpenMetrics written to %s", args.OpenMetricsPath)
+    }
 
-    // Skip header
-    if _, err := reader.Read(); err != nil {
-        return time.Time{}, fmt.Errorf("header read error: %v", err)
+    if args.Pushgateway != "" {
+        client := &http.Client{Timeout: args.Timeout}
+        if err := export.Push(ctx, client, args.Pushgateway, args.PushJob, result, result.Stages); err != nil {
+            return err
+        }
+        log.Printf("Metrics pushed to %s", args.Pushgateway)
     }
 
-    records, err := reader.ReadAll()
-    if err != nil {
-        return time.Time{}, fmt.Errorf("data read error: %v", err)
+    if args.OTLPFile != "" {
+        if err := export.WriteTraces(args.OTLPFile, result); err != nil {
+            return err
+        }
+        log.Printf("Traces written to %s", args.OTLPFile)
     }
 
-    if len(records) == 0 {
-        return time.Time{}, fmt.Errorf("no data rows found in %s", path)
+    if args.OTLPEndpoint != "" {
+        client := &http.Client{Timeout: args.Timeout}
+        if err := export.SendTraces(ctx, client, args.OTLPEndpoint, result); err != nil {
+            return err
+        }
+        log.Printf("Traces sent to %s", args.OTLPEndpoint)
     }
 
-    dataRow := records[0] // First data row
-    if len(dataRow) <= timeColumn {
-        return time.Time{}, fmt.Errorf("column %d missing in %s", timeColumn, path)
+    if args.ChromeTrace != "" {
+        if err := export.WriteChromeTrace(args.ChromeTrace, result); err != nil {
+            return err
+        }
+        log.Printf("Timeline written to %s", args.ChromeTrace)
     }
 
-    timeStr := strings.TrimSpace(dataRow[timeColumn])
-    if timeStr == "" {
-        return time.Time{}, fmt.Errorf("empty timestamp in column %d of %s", 
-            timeColumn, filepath.Base(path))
+    if args.HTMLReport != "" {
+        if err := report.WriteHTML(args.HTMLReport, result, result.Stages); err != nil {
+            return err
+        }
+        log.Printf("Report written to %s", args.HTMLReport)
     }
 
-    parsedTime, err := time.Parse(time.RFC3339, timeStr)
-    if err != nil {
-        return time.Time{}, fmt.Errorf("invalid time format in %s: %v", 
-            filepath.Base(path), err)
+    if args.Markdown != "" {
+        if err := report.WriteMarkdown(args.Markdown, result, result.Stages, checks); err != nil {
+            return err
+        }
+        log.Printf("Markdown summary written to %s", args.Markdown)
     }
 
-    return parsedTime, nil
+    if args.JUnit != "" {
+        if err := report.WriteJUnit(args.JUnit, result, checks); err != nil {
+            return err
+        }
+        log.Printf("JUnit report written to %s", args.JUnit)
+    }
+    return nil
 }
 
-func calculateAndPrintLatencies(data *LatencyData) {
-    fmt.Println("\n ====== KubeStellar Performance Results ======")
-
-    // Downsync metrics: always do “later.Sub(earlier)”
-    bindToWDS := data.WDSDeployCreate.Sub(data.BindingCreate)
-    pkgTime   := data.ManifestWorkCreate.Sub(data.WDSDeployCreate)    // manifest after binding
-    delivery  := data.ManifestWorkCreate.Sub(data.AppliedManifestCreate)
-    activate  := data.WECDeployCreate.Sub(data.AppliedManifestCreate)
-    totalDown := data.WECDeployCreate.Sub(data.WDSDeployCreate)
-
-    // Upsync: same principle
-    statusReport := data.WDSDeployStatus.Sub(data.WorkStatusUpdate)
-    finalization  := data.WDSDeployStatus.Sub(data.WECDeployStatus)
-    totalUp       := data.WDSDeployStatus.Sub(data.WECDeployStatus)
-
-    // End-to-end (WDS status minus WDS create)
-    e2e := data.WDSDeployStatus.Sub(data.WDSDeployCreate)
-
-    fmt.Println("\n Downsync Metrics")
-    fmt.Printf("  Binding→WDS deploy:  %v\n", bindToWDS.Round(time.Millisecond))
-    fmt.Printf("  Binding→Manifest pkg: %v\n", pkgTime.Round(time.Millisecond))
-    fmt.Printf("  Manifest→Applied MW:  %v\n", delivery.Round(time.Millisecond))
-    fmt.Printf("  Applied MW→WEC deploy: %v\n", activate.Round(time.Millisecond))
-    fmt.Printf("  Total Downsync:      %v\n", totalDown.Round(time.Millisecond))
-
-    fmt.Println("\n Upsync Metrics")
-    fmt.Printf("  WEC status→WDS status: %v\n", statusReport.Round(time.Millisecond))
-    fmt.Printf("  WEC status→WDS final:  %v\n", finalization.Round(time.Millisecond))
-    fmt.Printf("  Total Upsync:          %v\n", totalUp.Round(time.Millisecond))
-
-    fmt.Println("\n End-to-End Latency")
-    fmt.Printf("  WDS status-minus-WDS create: %v\n", e2e.Round(time.Millisecond))
-    fmt.Println("===========================================")
-}
+func getBindingCreationTime(ctx context.Context, wds *collector.Collector) (time.Time, error) {
+    // Get actual binding policy resource (not CRD)
+    created, err := wds.CreationTimestamp(ctx, collector.BindingPolicyGVR, "", collector.BindingPolicyName)
+    if err != nil {
+        return time.Time{}, fmt.Errorf("error getting binding policy: %v\nDid you create the binding policy after the deployment?", err)
+    }
 
+    return created, nil
+}
 
-func writeLatenciesToFile(data *LatencyData, outputDir string) error {
-    resultsPath := filepath.Join(outputDir, "latency_results.txt")
-    file, err := os.Create(resultsPath)
-    if err != nil {
+// writeIncompleteMarker drops an INCOMPLETE file into the output directory
+// explaining why the run stopped early.
+func writeIncompleteMarker(outputDir, reason string) error {
+    if err := os.MkdirAll(outputDir, 0755); err != nil {
         return err
     }
-    defer file.Close()
-
-    // Helper function to format durations safely
-    formatDuration := func(d time.Duration) string {
-        if d < 0 {
-            return "N/A (invalid timestamp order)"
-        }
-        return d.Round(time.Millisecond).String()
-    }
-
-    content := fmt.Sprintf(`KubeStellar Performance Metrics
-Every Timestamp used in below formulas:
-
-Binding Create: %s
-WDS Deploy Create: %s
-Manifest Work Create: %s
-Applied Manifest Create: %s
-WEC Deploy Create: %s
-WEC Deploy Status: %s
-WDS Deploy Status: %s
-Work Status Update: %s
-
-================================
-Downsync Metrics
-----------------
-Binding→WDS deploy:      %s
-Binding→Manifest pkg:    %s
-Manifest→Applied MW:     %s
-Applied MW→WEC deploy:   %s
-Total Downsync:          %s
-
-Upsync Metrics
---------------
-WEC status→WDS status:   %s
-WEC status→WDS final:    %s
-Total Upsync:            %s
-
-End-to-End Latency
-------------------
-Total Lifecycle:         %s`,
-        data.BindingCreate.Format(time.RFC3339),
-        data.WDSDeployCreate.Format(time.RFC3339),
-        data.ManifestWorkCreate.Format(time.RFC3339),
-        data.AppliedManifestCreate.Format(time.RFC3339),
-        data.WECDeployCreate.Format(time.RFC3339),
-        data.WECDeployStatus.Format(time.RFC3339),
-        data.WDSDeployStatus.Format(time.RFC3339),
-        data.WorkStatusUpdate.Format(time.RFC3339),
-
-        // Downsync: later.Sub(earlier)
-        formatDuration(data.WDSDeployCreate.Sub(data.BindingCreate)),              // Binding→WDS deploy
-        formatDuration(data.ManifestWorkCreate.Sub(data.WDSDeployCreate)),           // Binding→Manifest pkg
-        formatDuration(data.AppliedManifestCreate.Sub(data.ManifestWorkCreate)),   // Manifest→Applied MW
-        formatDuration(data.WECDeployCreate.Sub(data.AppliedManifestCreate)),      // Applied MW→WEC deploy
-        formatDuration(data.WECDeployCreate.Sub(data.WDSDeployCreate)),            // Total Downsync
-
-        // Upsync
-        formatDuration(data.WECDeployStatus.Sub(data.WorkStatusUpdate)),           // WEC status→WDS status (status report)
-        formatDuration(data.WorkStatusUpdate.Sub(data.WDSDeployStatus)),            // WEC status→WDS final (finalization)
-        formatDuration(data.WECDeployStatus.Sub(data.WDSDeployStatus)),            // Total Upsync
-
-        // End-to-End
-        formatDuration(data.WDSDeployStatus.Sub(data.WDSDeployCreate)),            // Total Lifecycle
-    )
-
-    _, err = file.WriteString(content)
-    return err
+    content := fmt.Sprintf("incomplete run: %s at %s\n", reason, time.Now().Format(time.RFC3339))
+    return os.WriteFile(filepath.Join(outputDir, "INCOMPLETE"), []byte(content), 0644)
 }
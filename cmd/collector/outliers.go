@@ -0,0 +1,38 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+    "github.com/asmit27rai/collector/pkg/report"
+)
+
+// consoleStragglers is how many stragglers are drilled into on the console;
+// outliers.txt has all of them.
+const consoleStragglers = 3
+
+// reportOutliers writes the outliers of result under rule to outliers.json
+// and outliers.txt and prints the worst of them.
+func reportOutliers(outputDir string, result *analysis.Result, rule analysis.OutlierRule) error {
+    if rule.Method == analysis.OutlierNone {
+        return nil
+    }
+    outliers := analysis.FindOutliers(result, rule)
+
+    data, err := json.MarshalIndent(outliers, "", "  ")
+    if err != nil {
+        return err
+    }
+    if err := os.WriteFile(filepath.Join(outputDir, "outliers.json"), append(data, '\n'), 0644); err != nil {
+        return err
+    }
+    if err := report.WriteOutliers(filepath.Join(outputDir, "outliers.txt"), outliers); err != nil {
+        return err
+    }
+
+    fmt.Println()
+    return report.Outliers(os.Stdout, outliers, consoleStragglers)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "strconv"
+    "text/tabwriter"
+
+    "github.com/asmit27rai/collector/pkg/collector"
+)
+
+func preflightCommand(argv []string) error {
+    flags := flag.NewFlagSet("preflight", flag.ExitOnError)
+    client := addClientFlags(flags)
+    clock := addClockFlags(flags)
+    flags.Parse(argv)
+
+    if flags.NArg() < 5 {
+        return errors.New("Usage: collector preflight [flags] <kubeconfig> <wds-context> <its-context> <wec-context> <num-ns>")
+    }
+    numNS, err := strconv.Atoi(flags.Arg(4))
+    if err != nil {
+        return fmt.Errorf("invalid num-ns %q: %v", flags.Arg(4), err)
+    }
+
+    args := collector.CollectionArgs{
+        Kubeconfig: flags.Arg(0),
+        WDSContext: flags.Arg(1),
+        ITSContext: flags.Arg(2),
+        WECContext: flags.Arg(3),
+        NumNS:      numNS,
+    }
+    if err := client.apply(&args); err != nil {
+        return err
+    }
+    clock.apply(&args)
+
+    wds, its, wec, err := newCollectors(args)
+    if err != nil {
+        return err
+    }
+
+    ctx, stop := signalContext()
+    defer stop()
+
+    if !runPreflight(ctx, args, wds, its, wec) {
+        os.Exit(1)
+    }
+    return nil
+}
+
+// runPreflight checks every cluster against the requirements of args, prints
+// a pass/fail table and reports whether everything passed.
+func runPreflight(ctx context.Context, args collector.CollectionArgs, wds, its, wec *collector.Collector) bool {
+    reqs := collector.Requirements(args)
+
+    var results []collector.CheckResult
+    results = append(results, wds.Preflight(ctx, collector.RoleWDS, reqs)...)
+    results = append(results, its.Preflight(ctx, collector.RoleITS, reqs)...)
+    results = append(results, wec.Preflight(ctx, collector.RoleWEC, reqs)...)
+
+    fmt.Println("\n ====== Preflight Checks ======")
+    tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(tw, "RESULT\tROLE\tCONTEXT\tCHECK\tTARGET\tDETAIL")
+
+    passed := true
+    for _, r := range results {
+        status := "PASS"
+        if !r.Passed {
+            status = "FAIL"
+            passed = false
+        }
+        fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", status, r.Role, r.Context, r.Check, r.Target, r.Detail)
+    }
+    tw.Flush()
+    fmt.Println()
+
+    return passed
+}
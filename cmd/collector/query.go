@@ -0,0 +1,39 @@
+package main
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "strings"
+    "text/tabwriter"
+
+    "github.com/asmit27rai/collector/pkg/store"
+)
+
+func queryCommand(argv []string) error {
+    flags := flag.NewFlagSet("query", flag.ExitOnError)
+    flags.Parse(argv)
+
+    if flags.NArg() < 2 {
+        return errors.New("Usage: collector query <sqlite-db> <sql>")
+    }
+
+    db, err := store.OpenSQLiteReadOnly(flags.Arg(0))
+    if err != nil {
+        return err
+    }
+    defer db.Close()
+
+    columns, rows, err := db.Query(strings.Join(flags.Args()[1:], " "))
+    if err != nil {
+        return err
+    }
+
+    tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(tw, strings.Join(columns, "\t"))
+    for _, row := range rows {
+        fmt.Fprintln(tw, strings.Join(row, "\t"))
+    }
+    return tw.Flush()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strconv"
+
+    "github.com/asmit27rai/collector/pkg/collector"
+    "github.com/asmit27rai/collector/pkg/rbac"
+    rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func rbacCommand(argv []string) error {
+    flags := flag.NewFlagSet("rbac", flag.ExitOnError)
+    subjectKind := flags.String("subject-kind", "User", "kind of the subject to bind: User, Group or ServiceAccount")
+    subjectName := flags.String("subject-name", "kubestellar-collector", "name of the subject to bind")
+    subjectNS := flags.String("subject-namespace", "default", "namespace of the subject when it is a ServiceAccount")
+    prefix := flags.String("name-prefix", "kubestellar-collector", "prefix for the generated role and binding names")
+    outputDir := flags.String("output-dir", "", "write one manifest file per cluster role here instead of stdout")
+    clock := addClockFlags(flags)
+    flags.Parse(argv)
+
+    if flags.NArg() < 4 {
+        return errors.New("Usage: collector rbac [flags] <wds-context> <its-context> <wec-context> <num-ns>")
+    }
+    numNS, err := strconv.Atoi(flags.Arg(3))
+    if err != nil {
+        return fmt.Errorf("invalid num-ns %q: %v", flags.Arg(3), err)
+    }
+
+    args := collector.CollectionArgs{
+        WDSContext: flags.Arg(0),
+        ITSContext: flags.Arg(1),
+        WECContext: flags.Arg(2),
+        NumNS:      numNS,
+    }
+    clock.apply(&args)
+
+    subject := rbacv1.Subject{Kind: *subjectKind, Name: *subjectName}
+    switch *subjectKind {
+    case rbacv1.ServiceAccountKind:
+        subject.Namespace = *subjectNS
+    case rbacv1.UserKind, rbacv1.GroupKind:
+        subject.APIGroup = rbacv1.GroupName
+    default:
+        return fmt.Errorf("unsupported subject kind %q", *subjectKind)
+    }
+
+    reqs := collector.Requirements(args)
+    contexts := map[collector.Role]string{
+        collector.RoleWDS: args.WDSContext,
+        collector.RoleITS: args.ITSContext,
+        collector.RoleWEC: args.WECContext,
+    }
+
+    for _, role := range []collector.Role{collector.RoleWDS, collector.RoleITS, collector.RoleWEC} {
+        manifest, err := rbac.YAML(rbac.Manifests(reqs, role, subject, *prefix))
+        if err != nil {
+            return err
+        }
+        header := fmt.Sprintf("# RBAC for the collector on the %s (context %s)\n", role, contexts[role])
+
+        if *outputDir == "" {
+            fmt.Print(header)
+            os.Stdout.Write(manifest)
+            continue
+        }
+
+        if err := os.MkdirAll(*outputDir, 0755); err != nil {
+            return err
+        }
+        path := filepath.Join(*outputDir, string(role)+"-rbac.yaml")
+        if err := os.WriteFile(path, append([]byte(header), manifest...), 0644); err != nil {
+            return err
+        }
+        fmt.Printf("Wrote %s\n", path)
+    }
+    return nil
+}
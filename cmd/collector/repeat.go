@@ -0,0 +1,294 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "log"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "text/tabwriter"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+    "github.com/asmit27rai/collector/pkg/collector"
+    "github.com/asmit27rai/collector/pkg/config"
+)
+
+// convergePoll is how often convergence is checked after loading.
+const convergePoll = 5 * time.Second
+
+// repeatSummary is summary.json of a repeated experiment.
+type repeatSummary struct {
+    Iterations   []string `json:"iterations"`
+    Runs         []string `json:"runs"`
+    Warmup       int      `json:"warmup"`
+    NotConverged []string `json:"notConverged"`
+    // Incomplete lists the measured iterations with failed collections;
+    // they are summarized with whatever was collected.
+    Incomplete []string `json:"incomplete"`
+    // Objects is the mean number of objects measured per iteration.
+    Objects float64                   `json:"objects"`
+    Stages  []analysis.StageAggregate `json:"stages"`
+}
+
+// runRepeated runs the load → converge → collect → cleanup cycle of
+// exp.Repeat, each iteration into its own subdirectory of args.OutputDir,
+// and summarizes the measured iterations with confidence intervals. env is
+// added to the environment of the load and cleanup commands. The summary is
+// returned along with errObjectives when an objective was violated, or else
+// errIncomplete when a measured iteration is incomplete.
+func runRepeated(ctx context.Context, wds, its, wec *collector.Collector, args collector.CollectionArgs, exp *config.Experiment, env []string) (*repeatSummary, error) {
+    r := exp.Repeat
+    var results []*analysis.Result
+    summary := repeatSummary{Warmup: r.Warmup, NotConverged: []string{}, Incomplete: []string{}}
+    violated := false
+
+    total := r.Warmup + r.Iterations
+    for i := 0; i < total; i++ {
+        warmup := i < r.Warmup
+        name := fmt.Sprintf("iteration-%d", i-r.Warmup+1)
+        if warmup {
+            name = fmt.Sprintf("warmup-%d", i+1)
+        }
+        iterArgs := iterationArgs(args, filepath.Join(args.OutputDir, name))
+        log.Printf("▶ %s (%d of %d)", name, i+1, total)
+
+        hookEnv := append(experimentEnv(iterArgs, name), env...)
+        converged, err := loadAndConverge(ctx, wds, wec, iterArgs, r, name, hookEnv)
+        if err != nil {
+            return nil, err
+        }
+        if !converged {
+            summary.NotConverged = append(summary.NotConverged, name)
+        }
+
+        err = collectShortExperiment(ctx, wds, its, wec, iterArgs, exp)
+        switch {
+        case errors.Is(err, errInterrupted):
+            return nil, err
+        case errors.Is(err, errObjectives):
+            violated = violated || !warmup
+        case errors.Is(err, errIncomplete):
+            log.Printf("⚠️  %s is incomplete, see %s/errors.json", name, iterArgs.OutputDir)
+            if !warmup {
+                summary.Incomplete = append(summary.Incomplete, name)
+            }
+        case err != nil:
+            return nil, fmt.Errorf("%s: %v", name, err)
+        }
+
+        if r.Cleanup != "" {
+            if err := runHook(ctx, "cleanup", r.Cleanup, hookEnv); err != nil {
+                return nil, err
+            }
+        }
+
+        if !warmup {
+            result, err := analysis.LoadRun(iterArgs.OutputDir)
+            if err != nil {
+                log.Printf("⚠️  %s left out of the summary: %v", name, err)
+            } else {
+                results = append(results, result)
+                summary.Objects += float64(analysis.ObjectCount(result))
+                summary.Iterations = append(summary.Iterations, name)
+                summary.Runs = append(summary.Runs, result.Run.ID)
+            }
+        }
+
+        if i < total-1 && r.Pause.Duration > 0 {
+            select {
+            case <-ctx.Done():
+                return nil, errInterrupted
+            case <-time.After(r.Pause.Duration):
+            }
+        }
+    }
+
+    if len(results) > 0 {
+        summary.Objects /= float64(len(results))
+    }
+    summary.Stages = analysis.Aggregate(results)
+    if err := writeRepeatSummary(args.OutputDir, summary); err != nil {
+        return nil, fmt.Errorf("error writing summary: %v", err)
+    }
+    log.Printf("✅ Summary of %d iterations written to %s/summary.txt", len(results), args.OutputDir)
+
+    if violated {
+        return &summary, errObjectives
+    }
+    if len(summary.Incomplete) > 0 {
+        return &summary, errIncomplete
+    }
+    return &summary, nil
+}
+
+// iterationArgs points the outputs of args at dir. Single-file outputs keep
+// their file name but move into dir, so iterations do not overwrite them.
+func iterationArgs(args collector.CollectionArgs, dir string) collector.CollectionArgs {
+    rebase := func(path string) string {
+        if path == "" {
+            return ""
+        }
+        return filepath.Join(dir, filepath.Base(path))
+    }
+    args.OutputDir = dir
+    args.OpenMetricsPath = rebase(args.OpenMetricsPath)
+    args.OTLPFile = rebase(args.OTLPFile)
+    args.ChromeTrace = rebase(args.ChromeTrace)
+    args.HTMLReport = rebase(args.HTMLReport)
+    args.Markdown = rebase(args.Markdown)
+    args.JUnit = rebase(args.JUnit)
+    return args
+}
+
+// loadAndConverge runs the load command and waits until every deployment on
+// the WDS is available on the WEC. It reports whether that happened before
+// the timeout; an iteration that does not converge is still collected.
+func loadAndConverge(ctx context.Context, wds, wec *collector.Collector, args collector.CollectionArgs, r *config.Repeat, name string, env []string) (bool, error) {
+    if r.Load != "" {
+        if err := runHook(ctx, "load", r.Load, env); err != nil {
+            return false, err
+        }
+    }
+
+    deadline := time.Now().Add(r.ConvergeTimeout.Duration)
+    for {
+        ready, total, err := convergence(ctx, wds, wec, args.NumNS)
+        if ctx.Err() != nil {
+            return false, errInterrupted
+        }
+        if err != nil {
+            log.Printf("checking convergence: %v", err)
+        } else if total > 0 && ready == total {
+            log.Printf("Converged: %d deployments available on %s", total, wec.Context)
+            return true, nil
+        }
+        if time.Now().After(deadline) {
+            log.Printf("⚠️  %s did not converge within %v (%d of %d deployments available); collecting anyway", name, r.ConvergeTimeout.Duration, ready, total)
+            return false, nil
+        }
+
+        select {
+        case <-ctx.Done():
+            return false, errInterrupted
+        case <-time.After(convergePoll):
+        }
+    }
+}
+
+// convergence counts the WDS deployments of the experiment namespaces and
+// how many of them are available on the WEC.
+func convergence(ctx context.Context, wds, wec *collector.Collector, numNS int) (int, int, error) {
+    ready, total := 0, 0
+    for i := 0; i < numNS; i++ {
+        ns := collector.ExperimentNamespace(i)
+        want, err := wds.CollectStandardObjects(ctx, "deployments", ns)
+        if err != nil {
+            return 0, 0, err
+        }
+        got, err := wec.CollectStandardObjects(ctx, "deployments", ns)
+        if err != nil {
+            return 0, 0, err
+        }
+
+        available := map[string]bool{}
+        for _, m := range got {
+            available[m.Name] = m.Condition == "Available"
+        }
+        for _, m := range want {
+            total++
+            if available[m.Name] {
+                ready++
+            }
+        }
+    }
+    return ready, total, nil
+}
+
+// experimentEnv describes an iteration to the load and cleanup commands.
+func experimentEnv(args collector.CollectionArgs, name string) []string {
+    return []string{
+        "KUBECONFIG=" + args.Kubeconfig,
+        "WDS_CONTEXT=" + args.WDSContext,
+        "ITS_CONTEXT=" + args.ITSContext,
+        "WEC_CONTEXT=" + args.WECContext,
+        "NUM_NS=" + strconv.Itoa(args.NumNS),
+        "OUTPUT_DIR=" + args.OutputDir,
+        "ITERATION=" + name,
+    }
+}
+
+// runHook runs a load or cleanup command through the shell.
+func runHook(ctx context.Context, hook, command string, env []string) error {
+    log.Printf("Running %s: %s", hook, command)
+    cmd := exec.CommandContext(ctx, "sh", "-c", command)
+    cmd.Env = append(os.Environ(), env...)
+    cmd.Stdout = os.Stdout
+    cmd.Stderr = os.Stderr
+    if err := cmd.Run(); err != nil {
+        if ctx.Err() != nil {
+            return errInterrupted
+        }
+        return fmt.Errorf("%s command failed: %v", hook, err)
+    }
+    return nil
+}
+
+// writeRepeatSummary writes summary.json and a summary.txt table of s.
+func writeRepeatSummary(dir string, s repeatSummary) error {
+    data, err := json.MarshalIndent(s, "", "  ")
+    if err != nil {
+        return err
+    }
+    if err := os.WriteFile(filepath.Join(dir, "summary.json"), append(data, '\n'), 0644); err != nil {
+        return err
+    }
+
+    f, err := os.Create(filepath.Join(dir, "summary.txt"))
+    if err != nil {
+        return err
+    }
+    printAggregates(io.MultiWriter(f, os.Stdout), s)
+    return f.Close()
+}
+
+// printAggregates prints each stage statistic as its mean across iterations
+// with the 95% confidence interval.
+func printAggregates(w io.Writer, s repeatSummary) {
+    fmt.Fprintf(w, "\n ====== %d iterations (%d warmup discarded), mean [95%% CI] ======\n", len(s.Iterations), s.Warmup)
+    if len(s.NotConverged) > 0 {
+        fmt.Fprintf(w, "Did not converge: %s\n", strings.Join(s.NotConverged, ", "))
+    }
+    if len(s.Incomplete) > 0 {
+        fmt.Fprintf(w, "Incomplete: %s\n", strings.Join(s.Incomplete, ", "))
+    }
+
+    tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+    header := []string{"STAGE", "ITERATIONS", "OBJECTS"}
+    if len(s.Stages) > 0 {
+        for _, m := range s.Stages[0].Metrics {
+            header = append(header, strings.ToUpper(m.Metric))
+        }
+    }
+    fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+    round := func(d time.Duration) time.Duration { return d.Round(time.Millisecond) }
+    for _, st := range s.Stages {
+        row := []string{st.Stage, strconv.Itoa(st.Iterations), strconv.Itoa(st.Objects)}
+        for _, m := range st.Metrics {
+            if m.Undefined {
+                row = append(row, fmt.Sprintf("%v [n/a]", round(m.Mean)))
+                continue
+            }
+            row = append(row, fmt.Sprintf("%v [%v, %v]", round(m.Mean), round(m.Low), round(m.High)))
+        }
+        fmt.Fprintln(tw, strings.Join(row, "\t"))
+    }
+    tw.Flush()
+}
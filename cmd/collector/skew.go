@@ -0,0 +1,48 @@
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "log"
+
+    "github.com/asmit27rai/collector/pkg/collector"
+)
+
+// clockFlags are the clock probe settings. They change what the collector
+// needs to be allowed to do, so the rbac and preflight subcommands take
+// them too.
+type clockFlags struct {
+    probes    *int
+    namespace *string
+}
+
+func addClockFlags(flags *flag.FlagSet) *clockFlags {
+    return &clockFlags{
+        probes:    flags.Int("clock-probes", 0, "write this many ConfigMaps to each cluster to estimate its clock offset; needs create and delete on ConfigMaps in -clock-namespace"),
+        namespace: flags.String("clock-namespace", "default", "namespace to write clock probes to"),
+    }
+}
+
+func (f *clockFlags) apply(args *collector.CollectionArgs) {
+    args.ClockProbes = *f.probes
+    args.ClockNamespace = *f.namespace
+}
+
+// probeClocks estimates the clock offset of every cluster. Clusters that
+// cannot be probed are left out and reported as warnings.
+func probeClocks(ctx context.Context, args collector.CollectionArgs, collectors ...*collector.Collector) ([]collector.ClockOffset, []string) {
+    var clocks []collector.ClockOffset
+    var warnings []string
+    for _, c := range collectors {
+        offset, err := c.ProbeClock(ctx, args.ClockNamespace, args.ClockProbes)
+        if err != nil {
+            log.Printf("⚠️  %v", err)
+            warnings = append(warnings, fmt.Sprintf("could not estimate the clock offset of %s: %v", c.Context, err))
+            continue
+        }
+        log.Printf("Clock offset of %s", offset)
+        clocks = append(clocks, offset)
+    }
+    return clocks, warnings
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+    "fmt"
+    "os"
+    "text/tabwriter"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+)
+
+// printChecks prints a pass/fail table of checks and reports whether all of
+// them passed.
+func printChecks(checks []analysis.Check) bool {
+    fmt.Println("\n ====== Latency Objectives ======")
+    tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(tw, "RESULT\tOBJECTIVE\tMEASURED")
+
+    passed := true
+    for _, c := range checks {
+        status := "PASS"
+        if !c.Passed {
+            status = "FAIL"
+            passed = false
+        }
+        fmt.Fprintf(tw, "%s\t%s\t%s\n", status, c.Name, c.Measured)
+    }
+    tw.Flush()
+    fmt.Println()
+
+    return passed
+}
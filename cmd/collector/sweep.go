@@ -0,0 +1,193 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "log"
+    "os"
+    "path/filepath"
+    "slices"
+    "strconv"
+    "strings"
+    "text/tabwriter"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+    "github.com/asmit27rai/collector/pkg/collector"
+    "github.com/asmit27rai/collector/pkg/config"
+    "github.com/asmit27rai/collector/pkg/report"
+)
+
+// sweepCell is one combination of sweep parameters; zero means the
+// parameter is not swept.
+type sweepCell struct {
+    namespaces, objects, wecs int
+}
+
+func (c sweepCell) name() string {
+    parts := []string{fmt.Sprintf("ns-%d", c.namespaces)}
+    if c.objects > 0 {
+        parts = append(parts, fmt.Sprintf("objects-%d", c.objects))
+    }
+    if c.wecs > 0 {
+        parts = append(parts, fmt.Sprintf("wecs-%d", c.wecs))
+    }
+    return strings.Join(parts, "_")
+}
+
+// env hands the cell's parameters to the load and cleanup commands.
+func (c sweepCell) env() []string {
+    var env []string
+    if c.objects > 0 {
+        env = append(env, "NUM_OBJECTS="+strconv.Itoa(c.objects))
+    }
+    if c.wecs > 0 {
+        env = append(env, "NUM_WECS="+strconv.Itoa(c.wecs))
+    }
+    return env
+}
+
+// sweepCells expands the matrix of s. Without namespaces the num-ns
+// argument is used.
+func sweepCells(s *config.Sweep, numNS int) []sweepCell {
+    orDefault := func(values []int, def int) []int {
+        if len(values) == 0 {
+            return []int{def}
+        }
+        return values
+    }
+
+    var cells []sweepCell
+    for _, ns := range orDefault(s.Namespaces, numNS) {
+        for _, objects := range orDefault(s.ObjectsPerNamespace, 0) {
+            for _, wecs := range orDefault(s.WECs, 0) {
+                cells = append(cells, sweepCell{ns, objects, wecs})
+            }
+        }
+    }
+    return cells
+}
+
+// runSweep runs the repeated experiment for every cell of exp.Sweep, each in
+// its own subdirectory of args.OutputDir, then writes the scaling table,
+// chart and fitted trends.
+func runSweep(ctx context.Context, wds, its, wec *collector.Collector, args collector.CollectionArgs, exp *config.Experiment) error {
+    cells := sweepCells(exp.Sweep, args.NumNS)
+    var points []analysis.ScalingPoint
+    violated, incomplete := false, false
+
+    for i, cell := range cells {
+        cellArgs := args
+        cellArgs.NumNS = cell.namespaces
+        cellArgs.OutputDir = filepath.Join(args.OutputDir, cell.name())
+        log.Printf("▶▶ Sweep cell %s (%d of %d)", cell.name(), i+1, len(cells))
+
+        summary, err := runRepeated(ctx, wds, its, wec, cellArgs, exp, cell.env())
+        switch {
+        case errors.Is(err, errInterrupted):
+            return err
+        case errors.Is(err, errObjectives):
+            violated = true
+        case errors.Is(err, errIncomplete):
+            incomplete = true
+        case err != nil:
+            return fmt.Errorf("cell %s: %v", cell.name(), err)
+        }
+
+        points = append(points, analysis.ScalingPoint{
+            Cell:                cell.name(),
+            Namespaces:          cell.namespaces,
+            ObjectsPerNamespace: cell.objects,
+            WECs:                cell.wecs,
+            Objects:             summary.Objects,
+            Stages:              summary.Stages,
+        })
+    }
+
+    trends := analysis.FitTrends(points, report.ScalingMetrics)
+    if err := writeScaling(args.OutputDir, points, trends); err != nil {
+        return fmt.Errorf("error writing scaling results: %v", err)
+    }
+    log.Printf("✅ Scaling results for %d cells written to %s/scaling.html", len(points), args.OutputDir)
+
+    if violated {
+        return errObjectives
+    }
+    if incomplete {
+        return errIncomplete
+    }
+    return nil
+}
+
+// writeScaling writes scaling.json, the scaling.txt table (also printed) and
+// the scaling.html chart.
+func writeScaling(dir string, points []analysis.ScalingPoint, trends []analysis.Trend) error {
+    data, err := json.MarshalIndent(struct {
+        Cells  []analysis.ScalingPoint `json:"cells"`
+        Trends []analysis.Trend        `json:"trends"`
+    }{points, trends}, "", "  ")
+    if err != nil {
+        return err
+    }
+    if err := os.WriteFile(filepath.Join(dir, "scaling.json"), append(data, '\n'), 0644); err != nil {
+        return err
+    }
+
+    f, err := os.Create(filepath.Join(dir, "scaling.txt"))
+    if err != nil {
+        return err
+    }
+    printScaling(io.MultiWriter(f, os.Stdout), points, trends)
+    if err := f.Close(); err != nil {
+        return err
+    }
+
+    return report.WriteScaling(filepath.Join(dir, "scaling.html"), points, trends)
+}
+
+// printScaling prints the mean P50 of every stage per cell, then the fitted
+// trends.
+func printScaling(w io.Writer, points []analysis.ScalingPoint, trends []analysis.Trend) {
+    var stages []string
+    for _, p := range points {
+        for _, s := range p.Stages {
+            if !slices.Contains(stages, s.Stage) {
+                stages = append(stages, s.Stage)
+            }
+        }
+    }
+
+    fmt.Fprintln(w, "\n ====== Scaling: mean P50 per cell ======")
+    tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(tw, strings.Join(append([]string{"CELL", "OBJECTS"}, stages...), "\t"))
+    for _, p := range points {
+        row := []string{p.Cell, fmt.Sprintf("%.0f", p.Objects)}
+        for _, stage := range stages {
+            if v, ok := p.Metric(stage, "p50"); ok {
+                row = append(row, fmt.Sprintf("%.3fs", v))
+            } else {
+                row = append(row, "-")
+            }
+        }
+        fmt.Fprintln(tw, strings.Join(row, "\t"))
+    }
+    tw.Flush()
+
+    fmt.Fprintln(w, "\n ====== Trends: latency = a * parameter^b, other parameters fixed ======")
+    tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(tw, "STAGE\tMETRIC\tPARAMETER\tFIXED\tB\tA\tR2\tCELLS\t")
+    for _, t := range trends {
+        fixed := t.Fixed
+        if fixed == "" {
+            fixed = "-"
+        }
+        flag := ""
+        if t.Superlinear {
+            flag = "⚠️ superlinear"
+        }
+        fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.2f\t%.3fs\t%.2f\t%d\t%s\n", t.Stage, t.Metric, t.Parameter, fixed, t.Exponent, t.Coefficient, t.R2, t.Points, flag)
+    }
+    tw.Flush()
+}
@@ -0,0 +1,129 @@
+package analysis
+
+import (
+    "math"
+    "slices"
+    "time"
+)
+
+// Interval is the mean of a statistic across iterations with its 95%
+// confidence interval. With fewer than two iterations there is no spread
+// to estimate: the interval is Undefined and Low and High are zero.
+type Interval struct {
+    Metric    string        `json:"metric"`
+    Mean      time.Duration `json:"mean"`
+    Low       time.Duration `json:"low"`
+    High      time.Duration `json:"high"`
+    StdDev    time.Duration `json:"stdDev"`
+    Undefined bool          `json:"undefined,omitempty"`
+}
+
+// StageAggregate is one stage across the iterations of a repeated
+// experiment. Iterations counts those where the stage was measured.
+type StageAggregate struct {
+    Stage      string     `json:"stage"`
+    Iterations int        `json:"iterations"`
+    Objects    int        `json:"objects"`
+    Metrics    []Interval `json:"metrics"`
+}
+
+// aggregateMetrics are the per-iteration statistics aggregated.
+var aggregateMetrics = []struct {
+    name string
+    of   func(Summary) time.Duration
+}{
+    {"mean", func(s Summary) time.Duration { return s.Mean }},
+    {"p50", func(s Summary) time.Duration { return s.P50 }},
+    {"p90", func(s Summary) time.Duration { return s.P90 }},
+    {"p99", func(s Summary) time.Duration { return s.P99 }},
+    {"max", func(s Summary) time.Duration { return s.Max }},
+}
+
+// Aggregate summarizes each stage per result and then each statistic across
+// results, treating the iterations as independent samples.
+func Aggregate(results []*Result) []StageAggregate {
+    var stages []string
+    perRun := make([]map[string][]time.Duration, len(results))
+    for i, r := range results {
+        perRun[i] = map[string][]time.Duration{}
+        for _, l := range r.Latencies {
+            if !slices.Contains(stages, l.Stage) {
+                stages = append(stages, l.Stage)
+            }
+            perRun[i][l.Stage] = append(perRun[i][l.Stage], l.Value)
+        }
+    }
+
+    var out []StageAggregate
+    for _, stage := range stages {
+        agg := StageAggregate{Stage: stage}
+        var summaries []Summary
+        for _, values := range perRun {
+            if len(values[stage]) == 0 {
+                continue
+            }
+            summaries = append(summaries, Summarize(values[stage]))
+            agg.Objects += len(values[stage])
+        }
+        agg.Iterations = len(summaries)
+
+        for _, m := range aggregateMetrics {
+            samples := make([]float64, len(summaries))
+            for i, s := range summaries {
+                samples[i] = float64(m.of(s))
+            }
+            agg.Metrics = append(agg.Metrics, confidenceInterval(m.name, samples))
+        }
+        out = append(out, agg)
+    }
+    return out
+}
+
+// confidenceInterval computes the mean of samples with a 95% interval from
+// Student's t-distribution. With fewer than two samples the interval is
+// undefined.
+func confidenceInterval(metric string, samples []float64) Interval {
+    n := float64(len(samples))
+    if n == 0 {
+        return Interval{Metric: metric, Undefined: true}
+    }
+    var sum float64
+    for _, v := range samples {
+        sum += v
+    }
+    mean := sum / n
+    iv := Interval{Metric: metric, Mean: time.Duration(mean)}
+    if n < 2 {
+        iv.Undefined = true
+        return iv
+    }
+
+    var ss float64
+    for _, v := range samples {
+        ss += (v - mean) * (v - mean)
+    }
+    sd := math.Sqrt(ss / (n - 1))
+    half := tQuantile975(len(samples)-1) * sd / math.Sqrt(n)
+    iv.StdDev = time.Duration(sd)
+    iv.Low = time.Duration(mean - half)
+    iv.High = time.Duration(mean + half)
+    return iv
+}
+
+// tTable holds the 0.975 quantiles of Student's t-distribution for 1 to 30
+// degrees of freedom.
+var tTable = []float64{
+    12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+    2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+    2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+func tQuantile975(df int) float64 {
+    if df < 1 {
+        return math.Inf(1)
+    }
+    if df <= len(tTable) {
+        return tTable[df-1]
+    }
+    return 1.960
+}
@@ -0,0 +1,43 @@
+package analysis
+
+import (
+    "testing"
+    "time"
+)
+
+func TestConfidenceInterval(t *testing.T) {
+    for _, tc := range []struct {
+        name            string
+        samples         []float64
+        mean, low, high time.Duration
+        stdDev          time.Duration
+        undefined       bool
+    }{
+        // t(0.975, 4) = 2.776, sd = sqrt(2.5).
+        {"five", []float64{1e9, 2e9, 3e9, 4e9, 5e9}, 3 * time.Second, 1037071575, 4962928424, 1581138830, false},
+        // t(0.975, 1) = 12.706, sd = sqrt(2) s.
+        {"two", []float64{1e9, 3e9}, 2 * time.Second, -10706000000, 14706000000, 1414213562, false},
+        {"equal", []float64{2e9, 2e9, 2e9}, 2 * time.Second, 2 * time.Second, 2 * time.Second, 0, false},
+        // One iteration gives a mean but no spread to build an interval on.
+        {"single", []float64{2e9}, 2 * time.Second, 0, 0, 0, true},
+        {"empty", nil, 0, 0, 0, 0, true},
+    } {
+        t.Run(tc.name, func(t *testing.T) {
+            iv := confidenceInterval("p50", tc.samples)
+            const slack = 2 // nanoseconds of rounding
+            if iv.Metric != "p50" || !approx(float64(iv.Mean), float64(tc.mean), slack) ||
+                !approx(float64(iv.Low), float64(tc.low), slack) || !approx(float64(iv.High), float64(tc.high), slack) ||
+                !approx(float64(iv.StdDev), float64(tc.stdDev), slack) || iv.Undefined != tc.undefined {
+                t.Errorf("confidenceInterval = %+v, want mean %v [%v, %v] sd %v undefined %v", iv, tc.mean, tc.low, tc.high, tc.stdDev, tc.undefined)
+            }
+        })
+    }
+}
+
+func TestTQuantile975(t *testing.T) {
+    for df, want := range map[int]float64{1: 12.706, 10: 2.228, 30: 2.042, 31: 1.960, 1000: 1.960} {
+        if got := tQuantile975(df); got != want {
+            t.Errorf("tQuantile975(%d) = %v, want %v", df, got, want)
+        }
+    }
+}
@@ -0,0 +1,42 @@
+package analysis
+
+import (
+    "fmt"
+)
+
+// Check is one pass/fail verdict on a run, such as a stage being measured
+// on every object or a latency objective being met. Measured and Threshold
+// are human-readable.
+type Check struct {
+    Suite     string
+    Name      string
+    Passed    bool
+    Measured  string
+    Threshold string
+}
+
+// Message explains the outcome of c, with the measured value against the
+// threshold.
+func (c Check) Message() string {
+    verdict := "met"
+    if !c.Passed {
+        verdict = "not met"
+    }
+    return fmt.Sprintf("%s: measured %s, want %s (%s)", c.Name, c.Measured, c.Threshold, verdict)
+}
+
+// StageChecks verifies that every stage was measured on every correlated
+// object and never came out negative.
+func StageChecks(r *Result) []Check {
+    var checks []Check
+    for _, q := range r.Quality {
+        checks = append(checks, Check{
+            Suite:     "stages",
+            Name:      q.Stage,
+            Passed:    q.Samples > 0 && q.Samples == q.Objects,
+            Measured:  fmt.Sprintf("%d of %d objects, %d objects missing events, %d negative", q.Samples, q.Objects, q.MissingTotal(), q.Negative),
+            Threshold: "all objects, none negative",
+        })
+    }
+    return checks
+}
@@ -0,0 +1,169 @@
+package analysis
+
+import (
+    "math"
+    "slices"
+    "sort"
+    "time"
+)
+
+// Verdicts of a stage comparison.
+const (
+    Unchanged   = "unchanged"
+    Regression  = "regression"
+    Improvement = "improvement"
+    NoData      = "no data"
+)
+
+// Delta is one statistic of a stage in two runs. Change is relative to the
+// baseline and zero when the baseline is.
+type Delta struct {
+    Metric    string        `json:"metric"`
+    Baseline  time.Duration `json:"baseline"`
+    Candidate time.Duration `json:"candidate"`
+    Change    float64       `json:"change"`
+}
+
+// StageComparison compares one stage's latencies in two runs. PValue is
+// from a two-sided Mann-Whitney U test of the two samples.
+type StageComparison struct {
+    Stage     string  `json:"stage"`
+    Baseline  int     `json:"baselineCount"`
+    Candidate int     `json:"candidateCount"`
+    Deltas    []Delta `json:"deltas"`
+    U         float64 `json:"u"`
+    PValue    float64 `json:"pValue"`
+    Verdict   string  `json:"verdict"`
+}
+
+// Comparison is a candidate run compared with a baseline run.
+type Comparison struct {
+    Baseline  Run               `json:"baseline"`
+    Candidate Run               `json:"candidate"`
+    Stages    []StageComparison `json:"stages"`
+}
+
+// Regressions counts the stages that regressed.
+func (c Comparison) Regressions() int {
+    n := 0
+    for _, s := range c.Stages {
+        if s.Verdict == Regression {
+            n++
+        }
+    }
+    return n
+}
+
+// Compare compares every stage of candidate with baseline. A stage regressed
+// (or improved) when the samples differ significantly at level alpha and
+// the median moved by more than tolerance, relative to the baseline.
+func Compare(baseline, candidate *Result, tolerance, alpha float64) Comparison {
+    c := Comparison{Baseline: baseline.Run, Candidate: candidate.Run}
+
+    // Stages in the order they were measured, baseline first.
+    var stages []string
+    values := func(r *Result) map[string][]time.Duration {
+        out := map[string][]time.Duration{}
+        for _, l := range r.Latencies {
+            if !slices.Contains(stages, l.Stage) {
+                stages = append(stages, l.Stage)
+            }
+            out[l.Stage] = append(out[l.Stage], l.Value)
+        }
+        return out
+    }
+    base, cand := values(baseline), values(candidate)
+
+    for _, stage := range stages {
+        b, a := Summarize(base[stage]), Summarize(cand[stage])
+        sc := StageComparison{Stage: stage, Baseline: b.Count, Candidate: a.Count, PValue: 1, Verdict: NoData}
+        for _, d := range []struct {
+            metric string
+            b, a   time.Duration
+        }{
+            {"mean", b.Mean, a.Mean},
+            {"p50", b.P50, a.P50},
+            {"p90", b.P90, a.P90},
+            {"p95", b.P95, a.P95},
+            {"p99", b.P99, a.P99},
+            {"max", b.Max, a.Max},
+        } {
+            sc.Deltas = append(sc.Deltas, Delta{Metric: d.metric, Baseline: d.b, Candidate: d.a, Change: relativeChange(d.b, d.a)})
+        }
+
+        if b.Count > 0 && a.Count > 0 {
+            sc.U, sc.PValue = MannWhitneyU(base[stage], cand[stage])
+            sc.Verdict = Unchanged
+            shift := float64(a.P50 - b.P50)
+            limit := tolerance * math.Abs(float64(b.P50))
+            switch {
+            case sc.PValue < alpha && shift > limit:
+                sc.Verdict = Regression
+            case sc.PValue < alpha && -shift > limit:
+                sc.Verdict = Improvement
+            }
+        }
+        c.Stages = append(c.Stages, sc)
+    }
+    return c
+}
+
+func relativeChange(baseline, candidate time.Duration) float64 {
+    if baseline == 0 {
+        return 0
+    }
+    return float64(candidate-baseline) / math.Abs(float64(baseline))
+}
+
+// MannWhitneyU tests whether x and y come from the same distribution. It
+// returns the U statistic of x and the two-sided p-value from the normal
+// approximation with tie and continuity corrections, which is reasonable
+// from about eight values per sample.
+func MannWhitneyU(x, y []time.Duration) (float64, float64) {
+    n1, n2 := float64(len(x)), float64(len(y))
+    if n1 == 0 || n2 == 0 {
+        return 0, 1
+    }
+
+    type sample struct {
+        v     time.Duration
+        first bool
+    }
+    pooled := make([]sample, 0, len(x)+len(y))
+    for _, v := range x {
+        pooled = append(pooled, sample{v, true})
+    }
+    for _, v := range y {
+        pooled = append(pooled, sample{v, false})
+    }
+    sort.Slice(pooled, func(i, j int) bool { return pooled[i].v < pooled[j].v })
+
+    // Tied values share the mean of their ranks.
+    var rankSum, ties float64
+    for i := 0; i < len(pooled); {
+        j := i
+        for j < len(pooled) && pooled[j].v == pooled[i].v {
+            j++
+        }
+        rank := float64(i+j+1) / 2
+        for k := i; k < j; k++ {
+            if pooled[k].first {
+                rankSum += rank
+            }
+        }
+        t := float64(j - i)
+        ties += t*t*t - t
+        i = j
+    }
+
+    u := rankSum - n1*(n1+1)/2
+    n := n1 + n2
+    mean := n1 * n2 / 2
+    variance := n1 * n2 / 12 * ((n + 1) - ties/(n*(n-1)))
+    if variance <= 0 {
+        return u, 1
+    }
+
+    z := math.Max(math.Abs(u-mean)-0.5, 0) / math.Sqrt(variance)
+    return u, math.Erfc(z / math.Sqrt2)
+}
@@ -0,0 +1,111 @@
+package analysis
+
+import (
+    "math"
+    "testing"
+    "time"
+)
+
+// seconds turns whole and fractional seconds into durations.
+func seconds(values ...float64) []time.Duration {
+    out := make([]time.Duration, len(values))
+    for i, v := range values {
+        out[i] = time.Duration(v * float64(time.Second))
+    }
+    return out
+}
+
+func span(from, to float64) []float64 {
+    var out []float64
+    for v := from; v <= to; v++ {
+        out = append(out, v)
+    }
+    return out
+}
+
+func approx(got, want, tolerance float64) bool {
+    return math.Abs(got-want) <= tolerance
+}
+
+// The reference p-values are those of scipy.stats.mannwhitneyu with
+// method="asymptotic" and use_continuity=True.
+func TestMannWhitneyU(t *testing.T) {
+    for _, tc := range []struct {
+        name string
+        x, y []time.Duration
+        u, p float64
+    }{
+        {"separated", seconds(1, 2, 3), seconds(4, 5, 6), 0, 0.0808556},
+        {"reversed", seconds(4, 5, 6), seconds(1, 2, 3), 9, 0.0808556},
+        {"ties", seconds(1, 2, 2, 3), seconds(2, 3, 3, 4), 3, 0.1720337},
+        {"overlapping", seconds(span(1, 10)...), seconds(span(6, 15)...), 12.5, 0.0050754},
+        {"identical", seconds(1, 2, 3), seconds(1, 2, 3), 4.5, 1},
+        {"all tied", seconds(5, 5), seconds(5, 5), 2, 1},
+        {"empty x", nil, seconds(1, 2), 0, 1},
+        {"empty y", seconds(1, 2), nil, 0, 1},
+    } {
+        t.Run(tc.name, func(t *testing.T) {
+            u, p := MannWhitneyU(tc.x, tc.y)
+            if u != tc.u || !approx(p, tc.p, 1e-6) {
+                t.Errorf("MannWhitneyU = %v, %.7f; want %v, %.7f", u, p, tc.u, tc.p)
+            }
+        })
+    }
+}
+
+func latencies(stage string, values []time.Duration) []Latency {
+    out := make([]Latency, len(values))
+    for i, v := range values {
+        out[i] = Latency{Stage: stage, Value: v}
+    }
+    return out
+}
+
+func TestCompare(t *testing.T) {
+    baseline := &Result{Run: Run{ID: "base"}}
+    baseline.Latencies = append(latencies("a", seconds(span(1, 10)...)), latencies("b", seconds(span(1, 10)...))...)
+    baseline.Latencies = append(baseline.Latencies, latencies("gone", seconds(1, 2))...)
+
+    candidate := &Result{Run: Run{ID: "cand"}}
+    candidate.Latencies = append(latencies("a", seconds(span(6, 15)...)), latencies("b", seconds(span(1, 10)...))...)
+    candidate.Latencies = append(candidate.Latencies, latencies("new", seconds(1, 2))...)
+
+    for _, tc := range []struct {
+        name      string
+        baseline  *Result
+        candidate *Result
+        tolerance float64
+        verdicts  map[string]string
+    }{
+        {"slower", baseline, candidate, 0.1, map[string]string{"a": Regression, "b": Unchanged, "gone": NoData, "new": NoData}},
+        {"faster", candidate, baseline, 0.1, map[string]string{"a": Improvement, "b": Unchanged}},
+        {"within tolerance", baseline, candidate, 2, map[string]string{"a": Unchanged, "b": Unchanged}},
+    } {
+        t.Run(tc.name, func(t *testing.T) {
+            c := Compare(tc.baseline, tc.candidate, tc.tolerance, 0.05)
+            got := map[string]string{}
+            for _, s := range c.Stages {
+                got[s.Stage] = s.Verdict
+            }
+            for stage, want := range tc.verdicts {
+                if got[stage] != want {
+                    t.Errorf("stage %s: verdict %q, want %q", stage, got[stage], want)
+                }
+            }
+        })
+    }
+
+    c := Compare(baseline, candidate, 0.1, 0.05)
+    if c.Regressions() != 1 {
+        t.Errorf("Regressions() = %d, want 1", c.Regressions())
+    }
+    a := c.Stages[0]
+    if a.Stage != "a" || a.U != 12.5 || !approx(a.PValue, 0.0050754, 1e-6) {
+        t.Errorf("stage a: U %v p %v, want U 12.5 p 0.0050754", a.U, a.PValue)
+    }
+    for _, d := range a.Deltas {
+        if d.Metric == "p50" && (d.Baseline != 5500*time.Millisecond || d.Candidate != 10500*time.Millisecond || !approx(d.Change, 10.0/11, 1e-9)) {
+            t.Errorf("p50 delta = %+v", d)
+        }
+    }
+}
@@ -0,0 +1,123 @@
+package analysis
+
+import (
+    "sort"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/collector"
+)
+
+// SizeClass is a range of object sizes from Min up to, not including, Max
+// bytes. Max 0 is unbounded.
+type SizeClass struct {
+    Name string
+    Min  int
+    Max  int
+}
+
+// SizeClasses break the downsync latency of every kind down by object size.
+var SizeClasses = []SizeClass{
+    {"<1KiB", 0, 1 << 10},
+    {"1-16KiB", 1 << 10, 16 << 10},
+    {"16-256KiB", 16 << 10, 256 << 10},
+    {">=256KiB", 256 << 10, 0},
+}
+
+func (c SizeClass) contains(size int) bool {
+    return size >= c.Min && (c.Max == 0 || size < c.Max)
+}
+
+// SizeSummary is the downsync latency of the objects of one kind in one
+// size class.
+type SizeSummary struct {
+    Class    string  `json:"class"`
+    MeanSize int     `json:"meanSize"`
+    Downsync Summary `json:"downsync"`
+}
+
+// KindSummary is the downsync latency of one kind, overall and per size
+// class with objects in it. Objects counts every object of the kind on the
+// WDS; Downsync only those delivered to a WEC.
+type KindSummary struct {
+    Kind     string        `json:"kind"`
+    Objects  int           `json:"objects"`
+    Downsync Summary       `json:"downsync"`
+    Sizes    []SizeSummary `json:"sizes"`
+}
+
+// Downsync is the time from l's creation on the WDS to its creation on the
+// WEC, whatever its kind, and whether it is known and not negative.
+func Downsync(l Lifecycle) (time.Duration, bool) {
+    start, ok := l.Time(Event{collector.RoleWDS, l.Kind, collector.StageCreated})
+    if !ok {
+        return 0, false
+    }
+    end, ok := l.Time(Event{collector.RoleWEC, l.Kind, collector.StageCreated})
+    if !ok || end.Before(start) {
+        return 0, false
+    }
+    return end.Sub(start), true
+}
+
+// CompareKinds summarises the downsync latency of lifecycles per kind, the
+// collected kinds first in their usual order.
+func CompareKinds(lifecycles []Lifecycle) []KindSummary {
+    type sample struct {
+        value time.Duration
+        size  int
+    }
+    objects := map[string]int{}
+    samples := map[string][]sample{}
+    for _, l := range lifecycles {
+        objects[l.Kind]++
+        if d, ok := Downsync(l); ok {
+            samples[l.Kind] = append(samples[l.Kind], sample{d, l.Size})
+        }
+    }
+
+    rank := func(kind string) int {
+        for i, k := range collector.StandardKinds {
+            if k == kind {
+                return i
+            }
+        }
+        return len(collector.StandardKinds)
+    }
+    kinds := make([]string, 0, len(objects))
+    for kind := range objects {
+        kinds = append(kinds, kind)
+    }
+    sort.Slice(kinds, func(i, j int) bool {
+        if a, b := rank(kinds[i]), rank(kinds[j]); a != b {
+            return a < b
+        }
+        return kinds[i] < kinds[j]
+    })
+
+    var out []KindSummary
+    for _, kind := range kinds {
+        ks := KindSummary{Kind: kind, Objects: objects[kind], Sizes: []SizeSummary{}}
+        var all []time.Duration
+        for _, s := range samples[kind] {
+            all = append(all, s.value)
+        }
+        ks.Downsync = Summarize(all)
+
+        for _, class := range SizeClasses {
+            var values []time.Duration
+            total := 0
+            for _, s := range samples[kind] {
+                // Size 0 is unknown, e.g. in data collected before sizes were.
+                if s.size > 0 && class.contains(s.size) {
+                    values = append(values, s.value)
+                    total += s.size
+                }
+            }
+            if len(values) > 0 {
+                ks.Sizes = append(ks.Sizes, SizeSummary{Class: class.Name, MeanSize: total / len(values), Downsync: Summarize(values)})
+            }
+        }
+        out = append(out, ks)
+    }
+    return out
+}
@@ -0,0 +1,224 @@
+package analysis
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/collector"
+)
+
+// Event identifies one timestamp in an object's journey: which kind of
+// object, on which cluster role, and which of its timestamps.
+type Event struct {
+    Role   collector.Role
+    Kind   string
+    Source string
+}
+
+func (e Event) String() string {
+    return fmt.Sprintf("%s/%s/%s", e.Role, e.Kind, e.Source)
+}
+
+func (e Event) MarshalText() ([]byte, error) {
+    return []byte(e.String()), nil
+}
+
+func (e *Event) UnmarshalText(text []byte) error {
+    parsed, err := ParseEvent(string(text))
+    if err != nil {
+        return err
+    }
+    *e = parsed
+    return nil
+}
+
+// ParseEvent reads an event written as role/kind/source.
+func ParseEvent(s string) (Event, error) {
+    parts := strings.Split(s, "/")
+    if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+        return Event{}, fmt.Errorf("invalid event %q, want role/kind/source", s)
+    }
+    return Event{Role: collector.Role(parts[0]), Kind: parts[1], Source: parts[2]}, nil
+}
+
+// Events every lifecycle may carry.
+var (
+    BindingCreated      = Event{collector.RoleWDS, collector.BindingPolicyGVR.Resource, collector.StageCreated}
+    WDSCreated          = Event{collector.RoleWDS, "deployments", collector.StageCreated}
+    WDSStatus           = Event{collector.RoleWDS, "deployments", collector.StageStatus}
+    ManifestWorkCreated = Event{collector.RoleITS, collector.ManifestWorkGVR.Resource, collector.StageCreated}
+    AppliedWorkCreated  = Event{collector.RoleWEC, collector.AppliedManifestWorkGVR.Resource, collector.StageCreated}
+    WECCreated          = Event{collector.RoleWEC, "deployments", collector.StageCreated}
+    WECStatus           = Event{collector.RoleWEC, "deployments", collector.StageStatus}
+    WECAvailable        = Event{collector.RoleWEC, "deployments", collector.StageAvailable}
+    WorkStatusCreated   = Event{collector.RoleITS, collector.WorkStatusGVR.Resource, collector.StageCreated}
+)
+
+// Events of a lifecycle's own object, whatever its kind. They leave the kind
+// empty, which Lifecycle.Time reads as the lifecycle's kind.
+var (
+    OwnWDSCreated   = Event{Role: collector.RoleWDS, Source: collector.StageCreated}
+    OwnWDSStatus    = Event{Role: collector.RoleWDS, Source: collector.StageStatus}
+    OwnWECCreated   = Event{Role: collector.RoleWEC, Source: collector.StageCreated}
+    OwnWECStatus    = Event{Role: collector.RoleWEC, Source: collector.StageStatus}
+    OwnWECAvailable = Event{Role: collector.RoleWEC, Source: collector.StageAvailable}
+)
+
+// Lifecycle is the journey of one workload object from the WDS through the
+// ITS to a WEC and back, as the timestamps of every related object.
+type Lifecycle struct {
+    Kind      string
+    Namespace string
+    Name      string
+    // Cluster is the WEC context the object was delivered to.
+    Cluster string
+    // ManifestWork is the name of the ManifestWork that carried the object.
+    ManifestWork string
+    // Size is the size in bytes of the object on the WDS.
+    Size   int
+    Events map[Event]time.Time
+    // Writes are the managedFields entries of every related object, oldest
+    // first.
+    Writes []Write
+}
+
+// Write is one managedFields entry of an object in a lifecycle.
+type Write struct {
+    Role collector.Role `json:"role"`
+    Kind string         `json:"kind"`
+    collector.Writer
+}
+
+// Time returns the timestamp of e, and whether it is known. An event
+// without a kind is one of l's own object.
+func (l Lifecycle) Time(e Event) (time.Time, bool) {
+    if e.Kind == "" {
+        e.Kind = l.Kind
+    }
+    t, ok := l.Events[e]
+    return t, ok && !t.IsZero()
+}
+
+// systemObjects are created by Kubernetes itself in every namespace of every
+// cluster rather than delivered by KubeStellar, so they have no lifecycle.
+var systemObjects = map[string]map[string]bool{
+    "configmaps": {"kube-root-ca.crt": true},
+}
+
+// Correlate builds one lifecycle per object found on the WDS, of every
+// collected kind, joining the records of the same object on the other
+// clusters. bindingCreated is stamped on every lifecycle when known.
+func Correlate(dataset *collector.Dataset, bindingCreated time.Time) []Lifecycle {
+    type key struct{ kind, namespace, name string }
+    wec := map[key]collector.ObjectMetrics{}
+    for _, m := range dataset.Objects {
+        if m.Role == collector.RoleWEC {
+            wec[key{m.Kind, m.Namespace, m.Name}] = m
+        }
+    }
+
+    var lifecycles []Lifecycle
+    for _, m := range dataset.Objects {
+        if m.Role != collector.RoleWDS || systemObjects[m.Kind][m.Name] {
+            continue
+        }
+
+        l := Lifecycle{
+            Kind:      m.Kind,
+            Namespace: m.Namespace,
+            Name:      m.Name,
+            Size:      m.Size,
+            Events:    map[Event]time.Time{},
+        }
+        if !bindingCreated.IsZero() {
+            l.Events[BindingCreated] = bindingCreated
+        }
+        addStages(l.Events, collector.RoleWDS, m.Kind, m.Stages)
+        l.Writes = addWrites(l.Writes, collector.RoleWDS, m.Kind, m.Writers)
+
+        if w, ok := wec[key{m.Kind, m.Namespace, m.Name}]; ok {
+            l.Cluster = w.Cluster
+            addStages(l.Events, collector.RoleWEC, w.Kind, w.Stages)
+            l.Writes = addWrites(l.Writes, collector.RoleWEC, w.Kind, w.Writers)
+        }
+
+        if mw, ok := findWork(dataset, collector.ManifestWorkGVR.Resource, m.Namespace, m.Name); ok {
+            l.ManifestWork = mw.Name
+            addStages(l.Events, collector.RoleITS, mw.Kind, mw.Stages)
+            l.Writes = addWrites(l.Writes, collector.RoleITS, mw.Kind, mw.Writers)
+
+            // AppliedManifestWorks are named <hub hash>-<ManifestWork name>.
+            for _, amw := range dataset.Works {
+                if amw.Kind == collector.AppliedManifestWorkGVR.Resource && strings.HasSuffix(amw.Name, "-"+mw.Name) {
+                    addStages(l.Events, collector.RoleWEC, amw.Kind, amw.Stages)
+                    l.Writes = addWrites(l.Writes, collector.RoleWEC, amw.Kind, amw.Writers)
+                    break
+                }
+            }
+        }
+
+        if ws, ok := findWork(dataset, collector.WorkStatusGVR.Resource, m.Namespace, m.Name); ok {
+            addStages(l.Events, collector.RoleITS, ws.Kind, ws.Stages)
+            l.Writes = addWrites(l.Writes, collector.RoleITS, ws.Kind, ws.Writers)
+        }
+        sort.SliceStable(l.Writes, func(i, j int) bool { return l.Writes[i].Time.Before(l.Writes[j].Time) })
+
+        lifecycles = append(lifecycles, l)
+    }
+
+    sort.Slice(lifecycles, func(i, j int) bool {
+        a, b := lifecycles[i], lifecycles[j]
+        if a.Namespace != b.Namespace {
+            return a.Namespace < b.Namespace
+        }
+        if a.Kind != b.Kind {
+            return a.Kind < b.Kind
+        }
+        return a.Name < b.Name
+    })
+    return lifecycles
+}
+
+// findWork picks the work object of kind produced by the binding of
+// namespace that refers to name. When none refers to it the object is left
+// without one, so its work events count as missing rather than borrowing
+// another object's.
+func findWork(dataset *collector.Dataset, kind, namespace, name string) (collector.WorkMetrics, bool) {
+    var candidates []collector.WorkMetrics
+    for _, w := range dataset.Works {
+        if w.Kind == kind && w.Binding == namespace {
+            candidates = append(candidates, w)
+        }
+    }
+    if len(candidates) == 0 {
+        return collector.WorkMetrics{}, false
+    }
+    sort.Slice(candidates, func(i, j int) bool { return candidates[i].Name < candidates[j].Name })
+
+    for _, w := range candidates {
+        if w.TargetObject == name || strings.HasSuffix(w.Name, "-"+name) {
+            return w, true
+        }
+        for _, t := range w.Targets {
+            if t == name {
+                return w, true
+            }
+        }
+    }
+    return collector.WorkMetrics{}, false
+}
+
+func addStages(events map[Event]time.Time, role collector.Role, kind string, stages map[string]time.Time) {
+    for source, t := range stages {
+        events[Event{Role: role, Kind: kind, Source: source}] = t
+    }
+}
+
+func addWrites(writes []Write, role collector.Role, kind string, writers []collector.Writer) []Write {
+    for _, w := range writers {
+        writes = append(writes, Write{Role: role, Kind: kind, Writer: w})
+    }
+    return writes
+}
@@ -0,0 +1,200 @@
+package analysis
+
+import (
+    "encoding/json"
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// FanOutSkew is a derived metric objectives can refer to like a stage: per
+// binding (experiment namespace), the time between its first and its last
+// object of any kind being created on a WEC.
+const FanOutSkew = "fan-out skew"
+
+// Objective is a latency objective: the given percentile of a stage, or of
+// FanOutSkew, must stay below Threshold (or at most Threshold when
+// Inclusive).
+type Objective struct {
+    Stage      string        `json:"stage"`
+    Percentile float64       `json:"percentile"`
+    Threshold  time.Duration `json:"-"`
+    Inclusive  bool          `json:"inclusive,omitempty"`
+}
+
+func (o Objective) String() string {
+    op := "<"
+    if o.Inclusive {
+        op = "<="
+    }
+    return fmt.Sprintf("p%s %s %s %s", strconv.FormatFloat(o.Percentile, 'f', -1, 64), o.Stage, op, o.Threshold)
+}
+
+// UnmarshalJSON accepts either an expression such as "p99 Total Downsync < 5s"
+// or an object with stage, percentile and threshold fields.
+func (o *Objective) UnmarshalJSON(data []byte) error {
+    var expr string
+    if err := json.Unmarshal(data, &expr); err == nil {
+        parsed, err := ParseObjective(expr)
+        if err != nil {
+            return err
+        }
+        *o = parsed
+        return nil
+    }
+
+    var raw struct {
+        Stage      string  `json:"stage"`
+        Percentile float64 `json:"percentile"`
+        Threshold  string  `json:"threshold"`
+        Inclusive  bool    `json:"inclusive"`
+    }
+    if err := json.Unmarshal(data, &raw); err != nil {
+        return err
+    }
+    threshold, err := time.ParseDuration(raw.Threshold)
+    if err != nil {
+        return fmt.Errorf("objective for %q: invalid threshold %q: %v", raw.Stage, raw.Threshold, err)
+    }
+    *o = Objective{Stage: raw.Stage, Percentile: raw.Percentile, Threshold: threshold, Inclusive: raw.Inclusive}
+    return o.validate()
+}
+
+// ParseObjective reads an objective written as a percentile, a stage name, a
+// comparison and a duration, in either order of the first two:
+// "p99 Total Downsync < 5s" or "fan-out skew p90 <= 2s".
+func ParseObjective(s string) (Objective, error) {
+    op, inclusive := "<", false
+    i := strings.Index(s, "<")
+    if i < 0 {
+        return Objective{}, fmt.Errorf("invalid objective %q, want e.g. \"p99 Total Downsync < 5s\"", s)
+    }
+    rest := s[i+1:]
+    if strings.HasPrefix(rest, "=") {
+        op, inclusive, rest = "<=", true, rest[1:]
+    }
+    threshold, err := time.ParseDuration(strings.TrimSpace(rest))
+    if err != nil {
+        return Objective{}, fmt.Errorf("invalid objective %q: threshold after %q: %v", s, op, err)
+    }
+
+    o := Objective{Threshold: threshold, Inclusive: inclusive, Percentile: -1}
+    var stage []string
+    for _, word := range strings.Fields(s[:i]) {
+        if p, ok := parsePercentile(word); ok && o.Percentile < 0 {
+            o.Percentile = p
+            continue
+        }
+        stage = append(stage, word)
+    }
+    if o.Percentile < 0 {
+        return Objective{}, fmt.Errorf("invalid objective %q: no percentile such as p99", s)
+    }
+    o.Stage = strings.Join(stage, " ")
+    return o, o.validate()
+}
+
+// parsePercentile reads a word such as p99 or p99.9.
+func parsePercentile(word string) (float64, bool) {
+    if len(word) < 2 || (word[0] != 'p' && word[0] != 'P') {
+        return 0, false
+    }
+    p, err := strconv.ParseFloat(word[1:], 64)
+    return p, err == nil
+}
+
+func (o Objective) validate() error {
+    if o.Stage == "" {
+        return fmt.Errorf("objective %q has no stage", o)
+    }
+    if o.Percentile <= 0 || o.Percentile > 100 {
+        return fmt.Errorf("objective %q: percentile must be in (0, 100]", o)
+    }
+    if o.Threshold <= 0 {
+        return fmt.Errorf("objective %q: threshold must be positive", o)
+    }
+    return nil
+}
+
+// CheckObjectives refers every objective to the stages it may name, so a
+// typo fails before any collection instead of after it.
+func CheckObjectives(objectives []Objective, stages []Stage) error {
+    known := map[string]bool{FanOutSkew: true}
+    for _, s := range stages {
+        known[s.Name] = true
+    }
+    for _, o := range objectives {
+        if !known[o.Stage] {
+            names := []string{FanOutSkew}
+            for _, s := range stages {
+                names = append(names, s.Name)
+            }
+            return fmt.Errorf("objective %q: unknown stage %q, want one of: %s", o, o.Stage, strings.Join(names, ", "))
+        }
+    }
+    return nil
+}
+
+// Evaluate checks every objective against r. An objective on a stage with
+// no measurements fails.
+func Evaluate(r *Result, objectives []Objective) []Check {
+    values := map[string][]time.Duration{FanOutSkew: FanOutSkews(r.AllLifecycles)}
+    for _, l := range r.Latencies {
+        values[l.Stage] = append(values[l.Stage], l.Value)
+    }
+
+    var checks []Check
+    for _, o := range objectives {
+        c := Check{Suite: "slo", Name: o.String(), Threshold: fmt.Sprintf("p%s < %s", strconv.FormatFloat(o.Percentile, 'f', -1, 64), o.Threshold)}
+        if o.Inclusive {
+            c.Threshold = strings.Replace(c.Threshold, "<", "<=", 1)
+        }
+
+        sorted := append([]time.Duration(nil), values[o.Stage]...)
+        if len(sorted) == 0 {
+            c.Measured = "no data"
+            checks = append(checks, c)
+            continue
+        }
+        sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+        v := Quantile(sorted, o.Percentile/100)
+        c.Measured = fmt.Sprintf("%v over %d values", v.Round(time.Millisecond), len(sorted))
+        c.Passed = v < o.Threshold || (o.Inclusive && v == o.Threshold)
+        checks = append(checks, c)
+    }
+    return checks
+}
+
+// FanOutSkews returns, per namespace, the time between its first and last
+// object being created on a WEC, whatever their kinds.
+func FanOutSkews(lifecycles []Lifecycle) []time.Duration {
+    type span struct{ first, last time.Time }
+    spans := map[string]*span{}
+    var order []string
+    for _, l := range lifecycles {
+        t, ok := l.Time(OwnWECCreated)
+        if !ok {
+            continue
+        }
+        s := spans[l.Namespace]
+        if s == nil {
+            spans[l.Namespace] = &span{t, t}
+            order = append(order, l.Namespace)
+            continue
+        }
+        if t.Before(s.first) {
+            s.first = t
+        }
+        if t.After(s.last) {
+            s.last = t
+        }
+    }
+
+    skews := make([]time.Duration, 0, len(order))
+    for _, ns := range order {
+        skews = append(skews, spans[ns].last.Sub(spans[ns].first))
+    }
+    return skews
+}
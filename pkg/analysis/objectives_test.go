@@ -0,0 +1,83 @@
+package analysis
+
+import (
+    "testing"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/collector"
+)
+
+func TestParseObjective(t *testing.T) {
+    for _, tc := range []struct {
+        expr string
+        want Objective
+        ok   bool
+    }{
+        {"p99 Total Downsync < 5s", Objective{Stage: "Total Downsync", Percentile: 99, Threshold: 5 * time.Second}, true},
+        {"fan-out skew p90 <= 2s", Objective{Stage: FanOutSkew, Percentile: 90, Threshold: 2 * time.Second, Inclusive: true}, true},
+        {"p99.9 Deployment→WEC<=500ms", Objective{Stage: "Deployment→WEC", Percentile: 99.9, Threshold: 500 * time.Millisecond, Inclusive: true}, true},
+        {"P50  Total Downsync  <  1m", Objective{Stage: "Total Downsync", Percentile: 50, Threshold: time.Minute}, true},
+        {"Total Downsync < 5s", Objective{}, false},
+        {"p0 Total Downsync < 5s", Objective{}, false},
+        {"p101 Total Downsync < 5s", Objective{}, false},
+        {"pabc Total Downsync < 5s", Objective{}, false},
+        {"p99 Total Downsync > 5s", Objective{}, false},
+        {"p99 Total Downsync < soon", Objective{}, false},
+        {"p99 Total Downsync < 0s", Objective{}, false},
+        {"p99 < 5s", Objective{}, false},
+    } {
+        got, err := ParseObjective(tc.expr)
+        if (err == nil) != tc.ok {
+            t.Errorf("ParseObjective(%q) error = %v, want ok %v", tc.expr, err, tc.ok)
+            continue
+        }
+        if tc.ok && got != tc.want {
+            t.Errorf("ParseObjective(%q) = %+v, want %+v", tc.expr, got, tc.want)
+        }
+    }
+}
+
+func TestEvaluate(t *testing.T) {
+    start := time.Date(2025, 5, 26, 15, 0, 0, 0, time.UTC)
+    created := func(kind, namespace string, after time.Duration) Lifecycle {
+        return Lifecycle{Kind: kind, Namespace: namespace, Events: map[Event]time.Time{
+            {collector.RoleWEC, kind, collector.StageCreated}: start.Add(after),
+        }}
+    }
+    r := &Result{
+        Latencies: latencies("A", seconds(span(1, 10)...)),
+        // One deployment per namespace: its skew comes from the other kinds.
+        AllLifecycles: []Lifecycle{
+            created("configmaps", "ns-0", 0),
+            created("deployments", "ns-0", time.Second),
+            created("secrets", "ns-0", 3*time.Second),
+            created("deployments", "ns-1", 0),
+            created("services", "ns-1", time.Second),
+        },
+    }
+
+    for _, tc := range []struct {
+        expr     string
+        passed   bool
+        measured string
+    }{
+        {"p50 A < 6s", true, "5.5s over 10 values"},
+        {"p50 A < 5.5s", false, "5.5s over 10 values"},
+        {"p50 A <= 5.5s", true, "5.5s over 10 values"},
+        {"p90 B < 1s", false, "no data"},
+        {"fan-out skew p100 < 2s", false, "3s over 2 values"},
+        {"p50 fan-out skew <= 2s", true, "2s over 2 values"},
+    } {
+        o, err := ParseObjective(tc.expr)
+        if err != nil {
+            t.Fatal(err)
+        }
+        checks := Evaluate(r, []Objective{o})
+        if len(checks) != 1 {
+            t.Fatalf("%s: got %d checks", tc.expr, len(checks))
+        }
+        if c := checks[0]; c.Passed != tc.passed || c.Measured != tc.measured || c.Suite != "slo" {
+            t.Errorf("%s: %+v, want passed %v measured %q", tc.expr, c, tc.passed, tc.measured)
+        }
+    }
+}
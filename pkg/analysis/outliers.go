@@ -0,0 +1,230 @@
+package analysis
+
+import (
+    "fmt"
+    "math"
+    "sort"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/collector"
+)
+
+// Outlier detection methods.
+const (
+    // OutlierMAD flags latencies whose modified z-score, the distance from
+    // the median in median absolute deviations, exceeds the threshold.
+    OutlierMAD = "mad"
+    // OutlierIQR flags latencies more than threshold interquartile ranges
+    // above the third quartile.
+    OutlierIQR = "iqr"
+    // OutlierNone turns detection off.
+    OutlierNone = "none"
+)
+
+// minOutlierSamples is the fewest latencies a stage needs before any of
+// them is called an outlier.
+const minOutlierSamples = 5
+
+// OutlierRule decides which latencies of a stage are outliers. Only slow
+// outliers are flagged: fast objects are not what hurts the p99.
+type OutlierRule struct {
+    Method string `json:"method"`
+    // Threshold is the modified z-score for MAD and the IQR multiple for
+    // IQR. Zero means 3.5 and 1.5 respectively.
+    Threshold float64 `json:"threshold,omitempty"`
+}
+
+// DefaultOutlierRule is used when an experiment does not configure one.
+var DefaultOutlierRule = OutlierRule{Method: OutlierMAD, Threshold: 3.5}
+
+// Validate checks the method and fills in the default threshold.
+func (r *OutlierRule) Validate() error {
+    switch r.Method {
+    case OutlierMAD:
+        if r.Threshold == 0 {
+            r.Threshold = 3.5
+        }
+    case OutlierIQR:
+        if r.Threshold == 0 {
+            r.Threshold = 1.5
+        }
+    case OutlierNone:
+        return nil
+    default:
+        return fmt.Errorf("unknown outlier method %q, want %s, %s or %s", r.Method, OutlierMAD, OutlierIQR, OutlierNone)
+    }
+    if r.Threshold < 0 {
+        return fmt.Errorf("outlier threshold must not be negative")
+    }
+    return nil
+}
+
+func (r OutlierRule) String() string {
+    switch r.Method {
+    case OutlierMAD:
+        return fmt.Sprintf("modified z-score > %g", r.Threshold)
+    case OutlierIQR:
+        return fmt.Sprintf("> Q3 + %g×IQR", r.Threshold)
+    }
+    return r.Method
+}
+
+// limit returns the latency above which sorted values are outliers, and
+// false when the rule cannot tell, such as for too few samples.
+func (r OutlierRule) limit(sorted []time.Duration) (time.Duration, bool) {
+    if len(sorted) < minOutlierSamples {
+        return 0, false
+    }
+    switch r.Method {
+    case OutlierMAD:
+        median := Quantile(sorted, 0.5)
+        deviations := make([]time.Duration, len(sorted))
+        var sum float64
+        for i, v := range sorted {
+            deviations[i] = v - median
+            if deviations[i] < 0 {
+                deviations[i] = -deviations[i]
+            }
+            sum += float64(deviations[i])
+        }
+        sort.Slice(deviations, func(i, j int) bool { return deviations[i] < deviations[j] })
+
+        // 1.4826 scales the MAD to the standard deviation of a normal
+        // distribution. Whole-second timestamps often make more than half
+        // the latencies equal, and the MAD zero; fall back to the mean
+        // absolute deviation, scaled likewise, as Iglewicz and Hoaglin do.
+        scale := 1.4826 * float64(Quantile(deviations, 0.5))
+        if scale == 0 {
+            scale = 1.2533 * sum / float64(len(sorted))
+        }
+        if scale == 0 {
+            return 0, false
+        }
+        return median + time.Duration(r.Threshold*scale), true
+    case OutlierIQR:
+        q1, q3 := Quantile(sorted, 0.25), Quantile(sorted, 0.75)
+        return q3 + time.Duration(r.Threshold*float64(q3-q1)), true
+    }
+    return 0, false
+}
+
+// Outlier is one latency above its stage's limit.
+type Outlier struct {
+    Stage string        `json:"stage"`
+    Value time.Duration `json:"value"`
+    Limit time.Duration `json:"limit"`
+}
+
+// StageOutliers summarises detection on one stage. Limit is zero when the
+// stage had too few or too uniform samples to judge.
+type StageOutliers struct {
+    Stage    string        `json:"stage"`
+    Samples  int           `json:"samples"`
+    Median   time.Duration `json:"median"`
+    Limit    time.Duration `json:"limit"`
+    Outliers int           `json:"outliers"`
+}
+
+// Straggler is an object that was an outlier in at least one stage, with
+// everything known about its journey.
+type Straggler struct {
+    Kind         string              `json:"kind"`
+    Namespace    string              `json:"namespace"`
+    Name         string              `json:"name"`
+    Cluster      string              `json:"cluster"`
+    ManifestWork string              `json:"manifestWork,omitempty"`
+    Events       map[Event]time.Time `json:"events"`
+    Writes       []Write             `json:"writes"`
+    Outliers     []Outlier           `json:"outliers"`
+}
+
+// Severity is how far the straggler's worst stage went over its limit, as
+// a multiple of the limit.
+func (s Straggler) Severity() float64 {
+    worst := 0.0
+    for _, o := range s.Outliers {
+        if o.Limit > 0 {
+            worst = math.Max(worst, float64(o.Value)/float64(o.Limit))
+        }
+    }
+    return worst
+}
+
+// OutlierReport is the result of outlier detection on a run.
+type OutlierReport struct {
+    Rule       OutlierRule     `json:"rule"`
+    Stages     []StageOutliers `json:"stages"`
+    Stragglers []Straggler     `json:"stragglers"`
+}
+
+// FindOutliers applies rule to every stage of r and collects the objects
+// behind the outliers, worst first.
+func FindOutliers(r *Result, rule OutlierRule) OutlierReport {
+    report := OutlierReport{Rule: rule, Stages: []StageOutliers{}, Stragglers: []Straggler{}}
+    if rule.Method == OutlierNone {
+        return report
+    }
+
+    type key struct{ kind, namespace, name, cluster string }
+    values := map[string][]time.Duration{}
+    for _, l := range r.Latencies {
+        values[l.Stage] = append(values[l.Stage], l.Value)
+    }
+
+    index := map[key]int{}
+    for _, s := range r.Stages {
+        sorted := append([]time.Duration(nil), values[s.Name]...)
+        sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+        so := StageOutliers{Stage: s.Name, Samples: len(sorted), Median: Quantile(sorted, 0.5)}
+
+        limit, ok := rule.limit(sorted)
+        if ok {
+            so.Limit = limit
+            for _, l := range r.Latencies {
+                if l.Stage != s.Name || l.Value <= limit {
+                    continue
+                }
+                so.Outliers++
+                k := key{l.Kind, l.Namespace, l.Name, l.Cluster}
+                i, seen := index[k]
+                if !seen {
+                    i = len(report.Stragglers)
+                    index[k] = i
+                    report.Stragglers = append(report.Stragglers, Straggler{Kind: l.Kind, Namespace: l.Namespace, Name: l.Name, Cluster: l.Cluster})
+                }
+                report.Stragglers[i].Outliers = append(report.Stragglers[i].Outliers, Outlier{Stage: s.Name, Value: l.Value, Limit: limit})
+            }
+        }
+        report.Stages = append(report.Stages, so)
+    }
+
+    for k, i := range index {
+        for _, l := range r.Lifecycles {
+            if l.Kind == k.kind && l.Namespace == k.namespace && l.Name == k.name && l.Cluster == k.cluster {
+                report.Stragglers[i].ManifestWork = l.ManifestWork
+                report.Stragglers[i].Events = l.Events
+                report.Stragglers[i].Writes = l.Writes
+                break
+            }
+        }
+    }
+    sort.SliceStable(report.Stragglers, func(i, j int) bool {
+        return report.Stragglers[i].Severity() > report.Stragglers[j].Severity()
+    })
+    return report
+}
+
+// Phase names the part of the journey that ends with e: transport from the
+// WDS to the WEC, the work agent applying manifests on the WEC, or status
+// return from the WEC to the WDS.
+func Phase(e Event) string {
+    switch {
+    case e == ManifestWorkCreated || e == AppliedWorkCreated:
+        return "transport"
+    case e.Role == collector.RoleWEC && e.Kind != AppliedWorkCreated.Kind:
+        return "work agent"
+    case e == WorkStatusCreated || e == WDSStatus:
+        return "status return"
+    }
+    return ""
+}
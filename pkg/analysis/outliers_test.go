@@ -0,0 +1,56 @@
+package analysis
+
+import (
+    "testing"
+    "time"
+)
+
+func TestOutlierLimit(t *testing.T) {
+    for _, tc := range []struct {
+        name   string
+        rule   OutlierRule
+        sorted []time.Duration
+        limit  time.Duration
+        ok     bool
+    }{
+        // Median 3s, MAD 1s: 3s + 3.5 × 1.4826s.
+        {"mad", OutlierRule{Method: OutlierMAD, Threshold: 3.5}, seconds(1, 2, 3, 4, 100), 8189100000, true},
+        // More than half tied at the median makes the MAD zero; the mean
+        // absolute deviation is 0.8s: 1s + 3.5 × 1.2533 × 0.8s.
+        {"mad ties", OutlierRule{Method: OutlierMAD, Threshold: 3.5}, seconds(1, 1, 1, 1, 5), 4509240000, true},
+        {"mad all equal", OutlierRule{Method: OutlierMAD, Threshold: 3.5}, seconds(2, 2, 2, 2, 2), 0, false},
+        // Q1 3s, Q3 7s: 7s + 1.5 × 4s.
+        {"iqr", OutlierRule{Method: OutlierIQR, Threshold: 1.5}, seconds(span(1, 9)...), 13 * time.Second, true},
+        {"iqr all equal", OutlierRule{Method: OutlierIQR, Threshold: 1.5}, seconds(2, 2, 2, 2, 2), 2 * time.Second, true},
+        {"too few", OutlierRule{Method: OutlierMAD, Threshold: 3.5}, seconds(1, 2, 3, 100), 0, false},
+        {"empty", OutlierRule{Method: OutlierIQR, Threshold: 1.5}, nil, 0, false},
+        {"none", OutlierRule{Method: OutlierNone}, seconds(span(1, 9)...), 0, false},
+    } {
+        t.Run(tc.name, func(t *testing.T) {
+            limit, ok := tc.rule.limit(tc.sorted)
+            if ok != tc.ok || !approx(float64(limit), float64(tc.limit), 1e3) {
+                t.Errorf("limit = %v, %v; want %v, %v", limit, ok, tc.limit, tc.ok)
+            }
+        })
+    }
+}
+
+func TestOutlierRuleValidate(t *testing.T) {
+    for _, tc := range []struct {
+        rule      OutlierRule
+        threshold float64
+        valid     bool
+    }{
+        {OutlierRule{Method: OutlierMAD}, 3.5, true},
+        {OutlierRule{Method: OutlierIQR}, 1.5, true},
+        {OutlierRule{Method: OutlierIQR, Threshold: 3}, 3, true},
+        {OutlierRule{Method: OutlierNone}, 0, true},
+        {OutlierRule{Method: OutlierMAD, Threshold: -1}, -1, false},
+        {OutlierRule{Method: "zscore"}, 0, false},
+    } {
+        err := tc.rule.Validate()
+        if (err == nil) != tc.valid || tc.rule.Threshold != tc.threshold {
+            t.Errorf("Validate(%+v) = %v, threshold %v; want valid %v, threshold %v", tc.rule, err, tc.rule.Threshold, tc.valid, tc.threshold)
+        }
+    }
+}
@@ -0,0 +1,89 @@
+package analysis
+
+import (
+    "fmt"
+    "sort"
+    "time"
+)
+
+// StageQuality counts, for one stage, how many correlated objects it could
+// be measured on and why the others could not. Ties are intervals of zero
+// between timestamps of whole-second resolution, such as creationTimestamp:
+// they are kept as samples but the true interval is anywhere below a second.
+type StageQuality struct {
+    Stage    string         `json:"stage"`
+    Objects  int            `json:"objects"`
+    Samples  int            `json:"samples"`
+    Missing  map[string]int `json:"missing,omitempty"`
+    Negative int            `json:"negative"`
+    Ties     int            `json:"ties"`
+}
+
+// MissingTotal is the number of objects missing at least one event.
+func (q StageQuality) MissingTotal() int {
+    return q.Objects - q.Samples - q.Negative
+}
+
+// Validate checks every stage of every lifecycle for missing events,
+// negative intervals and second-granularity ties.
+func Validate(lifecycles []Lifecycle, stages []Stage) []StageQuality {
+    var out []StageQuality
+    for _, s := range stages {
+        q := StageQuality{Stage: s.Name, Objects: len(lifecycles), Missing: map[string]int{}}
+        for _, l := range lifecycles {
+            start, okStart := l.Time(s.Start)
+            end, okEnd := l.Time(s.End)
+            if !okStart {
+                q.Missing[s.Start.String()]++
+            }
+            if !okEnd {
+                q.Missing[s.End.String()]++
+            }
+            switch {
+            case !okStart || !okEnd:
+            case end.Before(start):
+                q.Negative++
+            default:
+                q.Samples++
+                if end.Equal(start) && (wholeSecond(start) || wholeSecond(end)) {
+                    q.Ties++
+                }
+            }
+        }
+        out = append(out, q)
+    }
+    return out
+}
+
+func wholeSecond(t time.Time) bool {
+    return t.Nanosecond() == 0
+}
+
+// qualityWarnings turns the problems found by Validate into warnings.
+func qualityWarnings(quality []StageQuality) []string {
+    var out []string
+    for _, q := range quality {
+        if n := q.MissingTotal(); n > 0 {
+            var events []string
+            for e := range q.Missing {
+                events = append(events, e)
+            }
+            sort.Strings(events)
+            detail := ""
+            for i, e := range events {
+                if i > 0 {
+                    detail += ", "
+                }
+                detail += fmt.Sprintf("%s on %d", e, q.Missing[e])
+            }
+            out = append(out, fmt.Sprintf("%s: not measurable on %d of %d objects, missing %s", q.Stage, n, q.Objects, detail))
+        }
+        if q.Negative > 0 {
+            out = append(out, fmt.Sprintf("%s: negative on %d of %d objects and left out of the statistics; check clock skew between clusters", q.Stage, q.Negative, q.Objects))
+        }
+        if q.Ties > 0 {
+            out = append(out, fmt.Sprintf("%s: %d of %d samples are 0s between whole-second timestamps, the true interval is below 1s", q.Stage, q.Ties, q.Samples))
+        }
+    }
+    return out
+}
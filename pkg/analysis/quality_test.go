@@ -0,0 +1,51 @@
+package analysis
+
+import (
+    "reflect"
+    "testing"
+    "time"
+)
+
+func TestValidate(t *testing.T) {
+    start := time.Date(2025, 5, 26, 15, 0, 0, 0, time.UTC)
+    lifecycle := func(events map[Event]time.Time) Lifecycle {
+        return Lifecycle{Kind: "deployments", Events: events}
+    }
+    lifecycles := []Lifecycle{
+        lifecycle(map[Event]time.Time{WDSCreated: start, WECCreated: start.Add(1500 * time.Millisecond)}),
+        // Missing the end event.
+        lifecycle(map[Event]time.Time{WDSCreated: start}),
+        // Missing both: one object, two missing events.
+        lifecycle(map[Event]time.Time{}),
+        // Negative through clock skew.
+        lifecycle(map[Event]time.Time{WDSCreated: start, WECCreated: start.Add(-time.Second)}),
+        // A zero-second tie between whole-second timestamps.
+        lifecycle(map[Event]time.Time{WDSCreated: start, WECCreated: start}),
+        // Equal but with sub-second precision, so not a tie.
+        lifecycle(map[Event]time.Time{WDSCreated: start.Add(time.Millisecond), WECCreated: start.Add(time.Millisecond)}),
+    }
+
+    quality := Validate(lifecycles, []Stage{{Name: "s", Start: WDSCreated, End: WECCreated}})
+    if len(quality) != 1 {
+        t.Fatalf("got %d stage qualities, want 1", len(quality))
+    }
+    want := StageQuality{
+        Stage:    "s",
+        Objects:  6,
+        Samples:  3,
+        Missing:  map[string]int{WDSCreated.String(): 1, WECCreated.String(): 2},
+        Negative: 1,
+        Ties:     1,
+    }
+    if q := quality[0]; !reflect.DeepEqual(q, want) {
+        t.Errorf("Validate = %+v, want %+v", q, want)
+    }
+    if n := quality[0].MissingTotal(); n != 2 {
+        t.Errorf("MissingTotal = %d, want 2 objects", n)
+    }
+
+    checks := StageChecks(&Result{Quality: quality})
+    if want := "3 of 6 objects, 2 objects missing events, 1 negative"; len(checks) != 1 || checks[0].Measured != want || checks[0].Passed {
+        t.Errorf("StageChecks = %+v, want failed with %q", checks, want)
+    }
+}
@@ -0,0 +1,98 @@
+package analysis
+
+import (
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/collector"
+)
+
+// Run describes one collection run.
+type Run struct {
+    ID         string    `json:"id"`
+    Started    time.Time `json:"started"`
+    Finished   time.Time `json:"finished"`
+    OutputDir  string    `json:"outputDir"`
+    WDSContext string    `json:"wdsContext"`
+    ITSContext string    `json:"itsContext"`
+    WECContext string    `json:"wecContext"`
+    NumNS      int       `json:"numNS"`
+    // Complete is false when collection was interrupted or some lists failed.
+    Complete bool `json:"complete"`
+    // Clocks are the estimated API server clock offsets of the clusters,
+    // when probed. SkewCorrected is true when timestamps were corrected
+    // with them before measuring stages.
+    Clocks        []collector.ClockOffset `json:"clocks,omitempty"`
+    SkewCorrected bool                    `json:"skewCorrected,omitempty"`
+}
+
+// NewRun starts describing a run of args, identified by its start time to
+// the nanosecond, so back-to-back iterations of a repeated run never share
+// an ID and overwrite each other in a store.
+func NewRun(args collector.CollectionArgs, started time.Time) Run {
+    return Run{
+        ID:         started.UTC().Format("20060102T150405.000000000Z"),
+        Started:    started,
+        OutputDir:  args.OutputDir,
+        WDSContext: args.WDSContext,
+        ITSContext: args.ITSContext,
+        WECContext: args.WECContext,
+        NumNS:      args.NumNS,
+        Complete:   true,
+    }
+}
+
+// Result is everything a run produced: the raw records, the correlated
+// lifecycles and the latencies measured on them.
+type Result struct {
+    Run        Run
+    Dataset    *collector.Dataset
+    Lifecycles []Lifecycle
+    // AllLifecycles are the lifecycles of every collected kind, deployments
+    // included, for the metrics that span kinds.
+    AllLifecycles []Lifecycle
+    // Stages are the definitions the latencies were measured with.
+    Stages    []Stage
+    Latencies []Latency
+    // Quality is how many objects each stage was measured on and why the
+    // others were left out.
+    Quality []StageQuality
+    // Kinds compares the downsync latency of every collected kind, while
+    // Lifecycles and the stages cover deployments only.
+    Kinds []KindSummary
+    // Warnings are data-quality problems worth a look before trusting the
+    // numbers, such as objects that never reached a cluster.
+    Warnings []string
+}
+
+// Analyze correlates the records of a run and measures stages on the
+// deployments, and downsync on every kind.
+func Analyze(run Run, dataset *collector.Dataset, bindingCreated time.Time, stages []Stage) *Result {
+    all := Correlate(dataset, bindingCreated)
+    if run.SkewCorrected {
+        CorrectSkew(all, run.Clocks)
+    }
+    var lifecycles []Lifecycle
+    for _, l := range all {
+        if l.Kind == "deployments" {
+            lifecycles = append(lifecycles, l)
+        }
+    }
+    quality := Validate(lifecycles, stages)
+
+    var warnings []string
+    if !run.Complete {
+        warnings = append(warnings, "collection was incomplete; some objects may be missing")
+    }
+    warnings = append(warnings, skewWarnings(run)...)
+    return &Result{
+        Run:           run,
+        Dataset:       dataset,
+        Lifecycles:    lifecycles,
+        AllLifecycles: all,
+        Stages:        stages,
+        Latencies:     Latencies(lifecycles, stages),
+        Quality:       quality,
+        Kinds:         CompareKinds(all),
+        Warnings:      append(warnings, qualityWarnings(quality)...),
+    }
+}
@@ -0,0 +1,75 @@
+package analysis
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+
+    "github.com/asmit27rai/collector/pkg/writer"
+)
+
+// RunFile is the file in a run's output directory that holds its analysis,
+// so later commands can compare or aggregate runs without the clusters.
+const RunFile = "run.json"
+
+// runFileSchema is bumped whenever the layout of RunFile changes.
+const runFileSchema = 1
+
+type runFile struct {
+    Schema    int            `json:"schema"`
+    Run       Run            `json:"run"`
+    Stages    []Stage        `json:"stages"`
+    Latencies []Latency      `json:"latencies"`
+    Quality   []StageQuality `json:"quality"`
+    Warnings  []string       `json:"warnings"`
+}
+
+// WriteRunFile writes the run metadata, latencies and warnings of r to
+// RunFile in dir.
+func WriteRunFile(dir string, r *Result) error {
+    if err := os.MkdirAll(dir, 0755); err != nil {
+        return err
+    }
+    f := runFile{Schema: runFileSchema, Run: r.Run, Stages: r.Stages, Latencies: r.Latencies, Quality: r.Quality, Warnings: r.Warnings}
+    if f.Latencies == nil {
+        f.Latencies = []Latency{}
+    }
+    if f.Warnings == nil {
+        f.Warnings = []string{}
+    }
+
+    data, err := json.MarshalIndent(f, "", "  ")
+    if err != nil {
+        return err
+    }
+    return os.WriteFile(filepath.Join(dir, RunFile), append(data, '\n'), 0644)
+}
+
+// LoadRun reads the RunFile of a run directory, after checking that the
+// tables collected next to it have the current schema version. The result
+// has no dataset or lifecycles, only what RunFile records.
+func LoadRun(dir string) (*Result, error) {
+    data, err := os.ReadFile(filepath.Join(dir, RunFile))
+    if os.IsNotExist(err) {
+        // Older runs only kept one sample deployment's timings, which is
+        // nothing to compare or aggregate.
+        if _, statErr := os.Stat(filepath.Join(dir, "latency_results.txt")); statErr == nil {
+            return nil, fmt.Errorf("%s has no %s; it was collected by a version without per-object latencies and cannot be compared, collect it again", dir, RunFile)
+        }
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to load run: %v", err)
+    }
+    var f runFile
+    if err := json.Unmarshal(data, &f); err != nil {
+        return nil, fmt.Errorf("invalid %s in %s: %v", RunFile, dir, err)
+    }
+    if f.Schema != runFileSchema {
+        return nil, fmt.Errorf("%s in %s has schema %d, want %d", RunFile, dir, f.Schema, runFileSchema)
+    }
+    if err := writer.CheckSchema(dir); err != nil {
+        return nil, err
+    }
+    return &Result{Run: f.Run, Stages: f.Stages, Latencies: f.Latencies, Quality: f.Quality, Warnings: f.Warnings}, nil
+}
@@ -0,0 +1,227 @@
+package analysis
+
+import (
+    "fmt"
+    "math"
+    "slices"
+    "sort"
+    "strings"
+)
+
+// ScalingPoint is one cell of a parameter sweep: its parameters, the mean
+// number of objects correlated per iteration and its stage aggregates.
+type ScalingPoint struct {
+    Cell                string           `json:"cell"`
+    Namespaces          int              `json:"namespaces"`
+    ObjectsPerNamespace int              `json:"objectsPerNamespace,omitempty"`
+    WECs                int              `json:"wecs,omitempty"`
+    Objects             float64          `json:"objects"`
+    Stages              []StageAggregate `json:"stages"`
+}
+
+// Metric returns the mean across iterations of metric for stage, and
+// whether the point has it.
+func (p ScalingPoint) Metric(stage, metric string) (float64, bool) {
+    for _, s := range p.Stages {
+        if s.Stage != stage || s.Iterations == 0 {
+            continue
+        }
+        for _, m := range s.Metrics {
+            if m.Metric == metric {
+                return m.Mean.Seconds(), true
+            }
+        }
+    }
+    return 0, false
+}
+
+// Sweep parameters a trend can be fitted against.
+const (
+    ParamNamespaces          = "namespaces"
+    ParamObjectsPerNamespace = "objectsPerNamespace"
+    ParamWECs                = "wecs"
+)
+
+// ScalingParameters are the sweep parameters in reporting order.
+var ScalingParameters = []string{ParamNamespaces, ParamObjectsPerNamespace, ParamWECs}
+
+// Parameter returns the value of the sweep parameter name at p, zero when
+// it was not swept.
+func (p ScalingPoint) Parameter(name string) int {
+    switch name {
+    case ParamNamespaces:
+        return p.Namespaces
+    case ParamObjectsPerNamespace:
+        return p.ObjectsPerNamespace
+    case ParamWECs:
+        return p.WECs
+    }
+    return 0
+}
+
+// ScalingSlice is the cells of a sweep that differ only in Parameter,
+// ordered by it. Fixed describes the values the other swept parameters are
+// held at, e.g. "wecs=2"; it is empty when only Parameter was swept.
+type ScalingSlice struct {
+    Parameter string
+    Fixed     string
+    Points    []ScalingPoint
+}
+
+// Slices cuts points along every parameter into slices in which only that
+// parameter varies. Slices with fewer than two values of the parameter are
+// left out, since nothing can be read from them.
+func Slices(points []ScalingPoint) []ScalingSlice {
+    var out []ScalingSlice
+    for _, param := range ScalingParameters {
+        index := map[string]int{}
+        var cut []ScalingSlice
+        for _, p := range points {
+            if p.Parameter(param) <= 0 {
+                continue
+            }
+            var fixed []string
+            for _, other := range ScalingParameters {
+                if v := p.Parameter(other); other != param && v > 0 {
+                    fixed = append(fixed, fmt.Sprintf("%s=%d", other, v))
+                }
+            }
+            key := strings.Join(fixed, ", ")
+            i, ok := index[key]
+            if !ok {
+                i = len(cut)
+                index[key] = i
+                cut = append(cut, ScalingSlice{Parameter: param, Fixed: key})
+            }
+            cut[i].Points = append(cut[i].Points, p)
+        }
+
+        for _, sl := range cut {
+            sort.SliceStable(sl.Points, func(i, j int) bool { return sl.Points[i].Parameter(param) < sl.Points[j].Parameter(param) })
+            distinct := 0
+            for i, p := range sl.Points {
+                if i == 0 || p.Parameter(param) != sl.Points[i-1].Parameter(param) {
+                    distinct++
+                }
+            }
+            if distinct >= 2 {
+                out = append(out, sl)
+            }
+        }
+    }
+    return out
+}
+
+// Trend is a power law latency = Coefficient * value^Exponent fitted to a
+// stage metric along one sweep parameter, with the other parameters held
+// at Fixed. An exponent well above one means the stage grows superlinearly
+// with that parameter.
+type Trend struct {
+    Stage       string  `json:"stage"`
+    Metric      string  `json:"metric"`
+    Parameter   string  `json:"parameter"`
+    Fixed       string  `json:"fixed,omitempty"`
+    Coefficient float64 `json:"coefficient"`
+    Exponent    float64 `json:"exponent"`
+    R2          float64 `json:"r2"`
+    Points      int     `json:"points"`
+    Superlinear bool    `json:"superlinear"`
+}
+
+// superlinearExponent is the exponent above which a trend is flagged;
+// slightly above one so noise on a linear stage does not trip it.
+const superlinearExponent = 1.1
+
+// FitTrends fits a trend per slice of points, stage and metric through the
+// cells with a positive latency. A stage needs at least two distinct
+// parameter values within a slice.
+func FitTrends(points []ScalingPoint, metrics []string) []Trend {
+    var stages []string
+    for _, p := range points {
+        for _, s := range p.Stages {
+            if !slices.Contains(stages, s.Stage) {
+                stages = append(stages, s.Stage)
+            }
+        }
+    }
+
+    var trends []Trend
+    for _, sl := range Slices(points) {
+        for _, stage := range stages {
+            for _, metric := range metrics {
+                var xs, ys []float64
+                for _, p := range sl.Points {
+                    if y, ok := p.Metric(stage, metric); ok && y > 0 {
+                        xs = append(xs, float64(p.Parameter(sl.Parameter)))
+                        ys = append(ys, y)
+                    }
+                }
+                a, b, r2, ok := FitPowerLaw(xs, ys)
+                if !ok {
+                    continue
+                }
+                trends = append(trends, Trend{
+                    Stage:       stage,
+                    Metric:      metric,
+                    Parameter:   sl.Parameter,
+                    Fixed:       sl.Fixed,
+                    Coefficient: a,
+                    Exponent:    b,
+                    R2:          r2,
+                    Points:      len(xs),
+                    Superlinear: b > superlinearExponent,
+                })
+            }
+        }
+    }
+    return trends
+}
+
+// FitPowerLaw fits y = a * x^b by least squares on log x and log y, and
+// returns the fit's R² in log space. All values must be positive; ok is
+// false with fewer than two distinct x.
+func FitPowerLaw(xs, ys []float64) (a, b, r2 float64, ok bool) {
+    n := float64(len(xs))
+    if len(xs) < 2 || len(xs) != len(ys) {
+        return 0, 0, 0, false
+    }
+
+    var sx, sy, sxx, sxy float64
+    for i := range xs {
+        lx, ly := math.Log(xs[i]), math.Log(ys[i])
+        sx += lx
+        sy += ly
+        sxx += lx * lx
+        sxy += lx * ly
+    }
+    denom := n*sxx - sx*sx
+    if denom < 1e-12 {
+        return 0, 0, 0, false
+    }
+    b = (n*sxy - sx*sy) / denom
+    intercept := (sy - b*sx) / n
+
+    var ssRes, ssTot float64
+    meanY := sy / n
+    for i := range xs {
+        ly := math.Log(ys[i])
+        fit := intercept + b*math.Log(xs[i])
+        ssRes += (ly - fit) * (ly - fit)
+        ssTot += (ly - meanY) * (ly - meanY)
+    }
+    r2 = 1
+    if ssTot > 0 {
+        r2 = 1 - ssRes/ssTot
+    }
+    return math.Exp(intercept), b, r2, true
+}
+
+// ObjectCount is the number of distinct objects latencies were measured on.
+func ObjectCount(r *Result) int {
+    type key struct{ cluster, kind, namespace, name string }
+    seen := map[key]bool{}
+    for _, l := range r.Latencies {
+        seen[key{l.Cluster, l.Kind, l.Namespace, l.Name}] = true
+    }
+    return len(seen)
+}
@@ -0,0 +1,88 @@
+package analysis
+
+import (
+    "math"
+    "testing"
+    "time"
+)
+
+func TestFitPowerLaw(t *testing.T) {
+    for _, tc := range []struct {
+        name     string
+        xs, ys   []float64
+        a, b, r2 float64
+        ok       bool
+    }{
+        {"exact square", []float64{1, 2, 4, 8}, []float64{3, 12, 48, 192}, 3, 2, 1, true},
+        {"constant", []float64{10, 100, 1000}, []float64{2, 2, 2}, 2, 0, 1, true},
+        // numpy.polyfit(log(x), log(y), 1) gives the same slope and intercept.
+        {"noisy", []float64{1, 2, 3}, []float64{2, 3, 5}, 1.9186397, 0.8072222, 0.9539234, true},
+        {"one point", []float64{5}, []float64{1}, 0, 0, 0, false},
+        {"repeated x", []float64{5, 5, 5}, []float64{1, 2, 3}, 0, 0, 0, false},
+        {"length mismatch", []float64{1, 2}, []float64{1}, 0, 0, 0, false},
+        {"empty", nil, nil, 0, 0, 0, false},
+    } {
+        t.Run(tc.name, func(t *testing.T) {
+            a, b, r2, ok := FitPowerLaw(tc.xs, tc.ys)
+            if ok != tc.ok || !approx(a, tc.a, 1e-6) || !approx(b, tc.b, 1e-6) || !approx(r2, tc.r2, 1e-6) {
+                t.Errorf("FitPowerLaw = %v, %v, %v, %v; want %v, %v, %v, %v", a, b, r2, ok, tc.a, tc.b, tc.r2, tc.ok)
+            }
+            if ok && (math.IsNaN(a) || math.IsNaN(b) || math.IsNaN(r2)) {
+                t.Errorf("FitPowerLaw returned NaN")
+            }
+        })
+    }
+}
+
+func TestFitTrends(t *testing.T) {
+    // p50 = 0.1s * namespaces² * wecs, swept over a 3×2 matrix.
+    var points []ScalingPoint
+    for _, ns := range []int{1, 2, 4} {
+        for _, wecs := range []int{1, 2} {
+            p50 := time.Duration(0.1 * float64(ns*ns*wecs) * float64(time.Second))
+            points = append(points, ScalingPoint{
+                Namespaces: ns,
+                WECs:       wecs,
+                Stages:     []StageAggregate{{Stage: "s", Iterations: 1, Metrics: []Interval{{Metric: "p50", Mean: p50}}}},
+            })
+        }
+    }
+
+    type fit struct {
+        parameter, fixed string
+        exponent         float64
+        points           int
+    }
+    want := []fit{
+        {ParamNamespaces, "wecs=1", 2, 3},
+        {ParamNamespaces, "wecs=2", 2, 3},
+        {ParamWECs, "namespaces=1", 1, 2},
+        {ParamWECs, "namespaces=2", 1, 2},
+        {ParamWECs, "namespaces=4", 1, 2},
+    }
+    trends := FitTrends(points, []string{"p50"})
+    if len(trends) != len(want) {
+        t.Fatalf("got %d trends, want %d: %+v", len(trends), len(want), trends)
+    }
+    for i, tr := range trends {
+        w := want[i]
+        if tr.Parameter != w.parameter || tr.Fixed != w.fixed || !approx(tr.Exponent, w.exponent, 1e-9) || tr.Points != w.points {
+            t.Errorf("trend %d = %+v, want %+v", i, tr, w)
+        }
+        if tr.Superlinear != (w.exponent > superlinearExponent) {
+            t.Errorf("trend %d superlinear = %v", i, tr.Superlinear)
+        }
+    }
+}
+
+func TestSlicesNeedOneVaryingParameter(t *testing.T) {
+    // Both parameters change together, so neither varies on its own and
+    // there is nothing to fit, even though the object totals differ.
+    points := []ScalingPoint{
+        {Namespaces: 1, ObjectsPerNamespace: 10, Objects: 10},
+        {Namespaces: 2, ObjectsPerNamespace: 20, Objects: 40},
+    }
+    if got := Slices(points); len(got) != 0 {
+        t.Errorf("Slices = %+v, want none", got)
+    }
+}
@@ -0,0 +1,82 @@
+package analysis
+
+import (
+    "fmt"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/collector"
+)
+
+// skewRoles are the cluster roles whose timestamps stages subtract.
+var skewRoles = []collector.Role{collector.RoleWDS, collector.RoleITS, collector.RoleWEC}
+
+// MissingClocks returns the roles clocks has no offset for. Timestamps are
+// only worth correcting when it is empty: correcting some clusters but not
+// others leaves every stage between them off by the whole offset, which is
+// worse than correcting none.
+func MissingClocks(clocks []collector.ClockOffset) []collector.Role {
+    var missing []collector.Role
+    for _, role := range skewRoles {
+        found := false
+        for _, c := range clocks {
+            found = found || c.Role == role
+        }
+        if !found {
+            missing = append(missing, role)
+        }
+    }
+    return missing
+}
+
+// CorrectSkew moves every event and write time of lifecycles onto the local
+// clock by taking off the offset of the cluster role that stamped it. Roles
+// without an offset are left alone, so check MissingClocks first.
+func CorrectSkew(lifecycles []Lifecycle, clocks []collector.ClockOffset) {
+    offsets := map[collector.Role]time.Duration{}
+    for _, c := range clocks {
+        offsets[c.Role] = c.Offset
+    }
+    for _, l := range lifecycles {
+        for e, t := range l.Events {
+            if offset, ok := offsets[e.Role]; ok {
+                l.Events[e] = t.Add(-offset)
+            }
+        }
+        for i, w := range l.Writes {
+            if offset, ok := offsets[w.Role]; ok && !w.Time.IsZero() {
+                l.Writes[i].Time = w.Time.Add(-offset)
+            }
+        }
+    }
+}
+
+// skewWarnings flags clusters whose clock measurably differs from the
+// WDS's. Uncorrected, every stage crossing between them is off by as much.
+func skewWarnings(run Run) []string {
+    var wds *collector.ClockOffset
+    for i := range run.Clocks {
+        if run.Clocks[i].Role == collector.RoleWDS {
+            wds = &run.Clocks[i]
+        }
+    }
+    if wds == nil {
+        return nil
+    }
+
+    var out []string
+    for _, c := range run.Clocks {
+        if c.Role == collector.RoleWDS {
+            continue
+        }
+        skew, bound := c.Offset-wds.Offset, c.Error+wds.Error
+        switch {
+        case run.SkewCorrected:
+            if bound >= time.Second {
+                out = append(out, fmt.Sprintf("%s clock offset is only known to ±%v; stages between it and the wds are as uncertain", c.Role, bound.Round(time.Millisecond)))
+            }
+        case skew > bound || -skew > bound:
+            out = append(out, fmt.Sprintf("%s clock is %+v off the wds clock (±%v); stages between them are skewed by as much, rerun with -correct-skew", c.Role, skew.Round(time.Millisecond), bound.Round(time.Millisecond)))
+        }
+    }
+    return out
+}
@@ -0,0 +1,39 @@
+package analysis
+
+import (
+    "slices"
+    "testing"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/collector"
+)
+
+func TestMissingClocks(t *testing.T) {
+    wds := collector.ClockOffset{Role: collector.RoleWDS, Offset: time.Second}
+    its := collector.ClockOffset{Role: collector.RoleITS}
+    wec := collector.ClockOffset{Role: collector.RoleWEC, Offset: -time.Second}
+    for _, tc := range []struct {
+        clocks  []collector.ClockOffset
+        missing []collector.Role
+    }{
+        {[]collector.ClockOffset{wds, its, wec}, nil},
+        {[]collector.ClockOffset{wec, wds}, []collector.Role{collector.RoleITS}},
+        {nil, []collector.Role{collector.RoleWDS, collector.RoleITS, collector.RoleWEC}},
+    } {
+        if got := MissingClocks(tc.clocks); !slices.Equal(got, tc.missing) {
+            t.Errorf("MissingClocks(%v) = %v, want %v", tc.clocks, got, tc.missing)
+        }
+    }
+}
+
+func TestCorrectSkew(t *testing.T) {
+    at := time.Date(2025, 5, 26, 15, 0, 0, 0, time.UTC)
+    l := Lifecycle{Events: map[Event]time.Time{WDSCreated: at, WECCreated: at.Add(3 * time.Second)}}
+    CorrectSkew([]Lifecycle{l}, []collector.ClockOffset{
+        {Role: collector.RoleWDS, Offset: 500 * time.Millisecond},
+        {Role: collector.RoleWEC, Offset: 2 * time.Second},
+    })
+    if got := l.Events[WECCreated].Sub(l.Events[WDSCreated]); got != 1500*time.Millisecond {
+        t.Errorf("corrected downsync = %v, want 1.5s", got)
+    }
+}
@@ -0,0 +1,157 @@
+package analysis
+
+import (
+    "fmt"
+    "slices"
+    "strings"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/collector"
+)
+
+// Stage is a named latency: the time from its Start event to its End event.
+// Group is the heading reports list the stage under.
+type Stage struct {
+    Name  string `json:"name"`
+    Group string `json:"group,omitempty"`
+    Start Event  `json:"start"`
+    End   Event  `json:"end"`
+}
+
+// DefaultStages are the stages measured unless the experiment config
+// defines its own. Every stage is its later event minus its earlier one.
+var DefaultStages = []Stage{
+    {"Binding→WDS deploy", "Downsync", BindingCreated, WDSCreated},
+    {"Binding→Manifest pkg", "Downsync", WDSCreated, ManifestWorkCreated},
+    {"Manifest→Applied MW", "Downsync", ManifestWorkCreated, AppliedWorkCreated},
+    {"Applied MW→WEC deploy", "Downsync", AppliedWorkCreated, WECCreated},
+    {"Total Downsync", "Downsync", WDSCreated, WECCreated},
+    {"WEC status→WorkStatus", "Upsync", WECStatus, WorkStatusCreated},
+    {"WorkStatus→WDS status", "Upsync", WorkStatusCreated, WDSStatus},
+    {"Total Upsync", "Upsync", WECStatus, WDSStatus},
+    {"Total Lifecycle", "End-to-End", WDSCreated, WDSStatus},
+}
+
+// ValidateStages checks that every stage has a unique name and two
+// different, well-formed events that Deployment lifecycles carry.
+func ValidateStages(stages []Stage) error {
+    if len(stages) == 0 {
+        return fmt.Errorf("no stages defined")
+    }
+    seen := map[string]bool{FanOutSkew: true}
+    for i, s := range stages {
+        if s.Name == "" {
+            return fmt.Errorf("stage %d has no name", i+1)
+        }
+        if seen[s.Name] {
+            return fmt.Errorf("stage %q is defined twice or reuses a reserved name", s.Name)
+        }
+        seen[s.Name] = true
+        for _, e := range []Event{s.Start, s.End} {
+            if err := e.validate(); err != nil {
+                return fmt.Errorf("stage %q: %v", s.Name, err)
+            }
+        }
+        if s.Start == s.End {
+            return fmt.Errorf("stage %q starts and ends at %s", s.Name, s.Start)
+        }
+    }
+    return nil
+}
+
+// stageKind is a kind whose timestamps Deployment lifecycles carry, with
+// the timestamps collected for it.
+type stageKind struct {
+    kind    string
+    sources []string
+}
+
+var (
+    deploymentSources = []string{collector.StageCreated, collector.StageStatus, collector.StageAvailable}
+    workSources       = []string{collector.StageCreated, collector.StageUpdated}
+)
+
+// stageKinds are the kinds whose timestamps Deployment lifecycles carry, by
+// the role they are collected from. Stages are only measured on those
+// lifecycles, so an event of any other kind or timestamp would never
+// produce a sample.
+var stageKinds = map[collector.Role][]stageKind{
+    collector.RoleWDS: {
+        {"deployments", deploymentSources},
+        {collector.BindingPolicyGVR.Resource, []string{collector.StageCreated}},
+    },
+    collector.RoleITS: {
+        {collector.ManifestWorkGVR.Resource, workSources},
+        {collector.WorkStatusGVR.Resource, workSources},
+    },
+    collector.RoleWEC: {
+        {"deployments", deploymentSources},
+        {collector.AppliedManifestWorkGVR.Resource, workSources},
+    },
+}
+
+func (e Event) validate() error {
+    kinds, ok := stageKinds[e.Role]
+    if !ok {
+        return fmt.Errorf("event %s: unknown cluster role %q, want wds, its or wec", e, e.Role)
+    }
+    if e.Kind == "" {
+        return fmt.Errorf("event %s has no kind", e)
+    }
+    i := slices.IndexFunc(kinds, func(k stageKind) bool { return k.kind == e.Kind })
+    if i < 0 {
+        names := make([]string, len(kinds))
+        for j, k := range kinds {
+            names[j] = k.kind
+        }
+        return fmt.Errorf("event %s: no %s are measured on %s, want %s", e, e.Kind, e.Role, strings.Join(names, " or "))
+    }
+    if sources := kinds[i].sources; !slices.Contains(sources, e.Source) {
+        return fmt.Errorf("event %s: no %q timestamp is collected for %s on %s, want %s", e, e.Source, e.Kind, e.Role, strings.Join(sources, ", "))
+    }
+    return nil
+}
+
+// Latency is one stage measured on one object's lifecycle. Value is encoded
+// in nanoseconds.
+type Latency struct {
+    Stage     string        `json:"stage"`
+    Kind      string        `json:"kind"`
+    Namespace string        `json:"namespace"`
+    Name      string        `json:"name"`
+    Cluster   string        `json:"cluster"`
+    Start     time.Time     `json:"start"`
+    End       time.Time     `json:"end"`
+    Value     time.Duration `json:"value"`
+}
+
+// Latencies measures every stage on every lifecycle where both of the
+// stage's events are known. Negative intervals are left out: they come from
+// clock skew or wrong definitions, not from the system under test, and
+// Validate counts them.
+func Latencies(lifecycles []Lifecycle, stages []Stage) []Latency {
+    var out []Latency
+    for _, l := range lifecycles {
+        for _, s := range stages {
+            start, ok := l.Time(s.Start)
+            if !ok {
+                continue
+            }
+            end, ok := l.Time(s.End)
+            if !ok || end.Before(start) {
+                continue
+            }
+            out = append(out, Latency{
+                Stage:     s.Name,
+                Kind:      l.Kind,
+                Namespace: l.Namespace,
+                Name:      l.Name,
+                Cluster:   l.Cluster,
+                Start:     start,
+                End:       end,
+                Value:     end.Sub(start),
+            })
+        }
+    }
+    return out
+}
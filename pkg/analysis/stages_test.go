@@ -0,0 +1,48 @@
+package analysis
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestValidateStages(t *testing.T) {
+    stage := func(start, end string) []Stage {
+        s := Stage{Name: "s"}
+        var err error
+        if s.Start, err = ParseEvent(start); err != nil {
+            t.Fatal(err)
+        }
+        if s.End, err = ParseEvent(end); err != nil {
+            t.Fatal(err)
+        }
+        return []Stage{s}
+    }
+
+    if err := ValidateStages(DefaultStages); err != nil {
+        t.Errorf("DefaultStages: %v", err)
+    }
+    for _, tc := range []struct {
+        name   string
+        stages []Stage
+        err    string
+    }{
+        {"valid", stage("its/manifestworks/created", "wec/appliedmanifestworks/created"), ""},
+        {"other kind", stage("wds/configmaps/created", "wec/configmaps/created"), "no configmaps are measured on wds"},
+        {"typo", stage("wds/deployment/created", "wec/deployments/created"), "no deployment are measured on wds"},
+        {"wrong role", stage("wds/manifestworks/created", "wec/deployments/created"), "want deployments or bindingpolicies"},
+        {"unknown role", stage("hub/deployments/created", "wec/deployments/created"), "unknown cluster role"},
+        {"unknown timestamp", stage("wds/deployments/deleted", "wec/deployments/created"), `no "deleted" timestamp`},
+        {"policy status", stage("wds/bindingpolicies/status", "wds/deployments/created"), `no "status" timestamp is collected for bindingpolicies`},
+        {"work status", stage("its/manifestworks/status", "wec/deployments/created"), `no "status" timestamp is collected for manifestworks`},
+        {"deployment updated", stage("wds/deployments/created", "wec/deployments/updated"), "want created, status, available"},
+        {"same event", stage("wds/deployments/created", "wds/deployments/created"), "starts and ends"},
+        {"none", nil, "no stages"},
+    } {
+        t.Run(tc.name, func(t *testing.T) {
+            err := ValidateStages(tc.stages)
+            if tc.err == "" && err != nil || tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+                t.Errorf("ValidateStages = %v, want error containing %q", err, tc.err)
+            }
+        })
+    }
+}
@@ -0,0 +1,104 @@
+package analysis
+
+import (
+    "math"
+    "sort"
+    "time"
+)
+
+// Summary describes the distribution of one stage's latencies.
+type Summary struct {
+    Count int
+    Sum   time.Duration
+    Min   time.Duration
+    Max   time.Duration
+    Mean  time.Duration
+    P50   time.Duration
+    P90   time.Duration
+    P95   time.Duration
+    P99   time.Duration
+}
+
+// Summarize computes the distribution of values. It returns a zero Summary
+// for no values.
+func Summarize(values []time.Duration) Summary {
+    if len(values) == 0 {
+        return Summary{}
+    }
+    sorted := append([]time.Duration(nil), values...)
+    sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+    var sum time.Duration
+    for _, v := range sorted {
+        sum += v
+    }
+    return Summary{
+        Count: len(sorted),
+        Sum:   sum,
+        Min:   sorted[0],
+        Max:   sorted[len(sorted)-1],
+        Mean:  sum / time.Duration(len(sorted)),
+        P50:   Quantile(sorted, 0.50),
+        P90:   Quantile(sorted, 0.90),
+        P95:   Quantile(sorted, 0.95),
+        P99:   Quantile(sorted, 0.99),
+    }
+}
+
+// Quantile returns the q-quantile of sorted values, interpolating linearly
+// between the closest ranks.
+func Quantile(sorted []time.Duration, q float64) time.Duration {
+    if len(sorted) == 0 {
+        return 0
+    }
+    pos := q * float64(len(sorted)-1)
+    lo := int(math.Floor(pos))
+    hi := int(math.Ceil(pos))
+    if lo == hi {
+        return sorted[lo]
+    }
+    frac := pos - float64(lo)
+    return sorted[lo] + time.Duration(frac*float64(sorted[hi]-sorted[lo]))
+}
+
+// Series is the latencies of one stage for one kind on one cluster.
+type Series struct {
+    Stage   string
+    Kind    string
+    Cluster string
+    Values  []time.Duration
+}
+
+// GroupLatencies splits latencies into series by stage, kind and cluster,
+// ordered as the stages are and then by kind and cluster.
+func GroupLatencies(latencies []Latency, stages []Stage) []Series {
+    type key struct{ stage, kind, cluster string }
+    index := map[key]int{}
+    var series []Series
+    for _, l := range latencies {
+        k := key{l.Stage, l.Kind, l.Cluster}
+        i, ok := index[k]
+        if !ok {
+            i = len(series)
+            index[k] = i
+            series = append(series, Series{Stage: l.Stage, Kind: l.Kind, Cluster: l.Cluster})
+        }
+        series[i].Values = append(series[i].Values, l.Value)
+    }
+
+    order := map[string]int{}
+    for i, s := range stages {
+        order[s.Name] = i
+    }
+    sort.SliceStable(series, func(i, j int) bool {
+        a, b := series[i], series[j]
+        if order[a.Stage] != order[b.Stage] {
+            return order[a.Stage] < order[b.Stage]
+        }
+        if a.Kind != b.Kind {
+            return a.Kind < b.Kind
+        }
+        return a.Cluster < b.Cluster
+    })
+    return series
+}
@@ -0,0 +1,253 @@
+package analysis
+
+import (
+    "encoding/csv"
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "time"
+)
+
+// MaxThroughputBuckets bounds the length of the throughput time series, so
+// a namespace that lived for days does not make a chart of a million points.
+const MaxThroughputBuckets = 1000
+
+// ThroughputIntervals are the bucket widths to choose from, finest first.
+var ThroughputIntervals = []time.Duration{
+    time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second, 15 * time.Second, 30 * time.Second,
+    time.Minute, 2 * time.Minute, 5 * time.Minute, 10 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+    time.Hour, 2 * time.Hour, 6 * time.Hour, 12 * time.Hour, 24 * time.Hour,
+}
+
+// throughputInterval picks the finest of ThroughputIntervals that covers
+// first to last in at most MaxThroughputBuckets buckets, or else the
+// coarsest.
+func throughputInterval(first, last time.Time) time.Duration {
+    for _, interval := range ThroughputIntervals {
+        if last.Sub(first.Truncate(interval))/interval < MaxThroughputBuckets {
+            return interval
+        }
+    }
+    return ThroughputIntervals[len(ThroughputIntervals)-1]
+}
+
+// Units of a TimeSeries.
+const (
+    UnitRate    = "objects/s"
+    UnitBacklog = "objects"
+)
+
+// TimeSeries is one named time series. Rates are events per second within
+// each bucket; backlogs are the objects in flight at the end of each bucket.
+type TimeSeries struct {
+    Name   string    `json:"name"`
+    Unit   string    `json:"unit"`
+    Values []float64 `json:"values"`
+}
+
+// Peak returns the highest value of s and the bucket it is in.
+func (s TimeSeries) Peak() (float64, int) {
+    peak, at := 0.0, 0
+    for i, v := range s.Values {
+        if v > peak {
+            peak, at = v, i
+        }
+    }
+    return peak, at
+}
+
+// Throughput shows whether the system kept up with the load: how fast
+// objects passed each milestone and how many were queued between them.
+// Bucket i of every series starts at Start + i*Interval.
+type Throughput struct {
+    Start    time.Time     `json:"start"`
+    Interval time.Duration `json:"interval"`
+    Series   []TimeSeries  `json:"series"`
+}
+
+// statusKinds are the kinds whose status is returned to the WDS. Delivered
+// objects of other kinds never wait for one.
+var statusKinds = map[string]bool{"deployments": true}
+
+// ComputeThroughput buckets the milestones of lifecycles, of any kind, by
+// the finest interval that keeps the series within MaxThroughputBuckets.
+// There is a rate series for objects created on the WDS, ManifestWorks
+// created on the ITS, objects created on each WEC and statuses returned to
+// the WDS, and backlog series for objects not yet delivered to a WEC and
+// delivered objects of statusKinds whose status has not come back yet.
+func ComputeThroughput(lifecycles []Lifecycle) Throughput {
+    var wdsCreated, workCreated, statuses []time.Time
+    wecCreated := map[string][]time.Time{}
+    var inFlight, awaitingStatus [][2]time.Time
+    works := map[string]bool{}
+
+    for _, l := range lifecycles {
+        created, okCreated := l.Time(OwnWDSCreated)
+        delivered, okDelivered := l.Time(OwnWECCreated)
+        status, okStatus := l.Time(OwnWDSStatus)
+        if okCreated {
+            wdsCreated = append(wdsCreated, created)
+            inFlight = append(inFlight, [2]time.Time{created, delivered})
+        }
+        if okDelivered {
+            wecCreated[l.Cluster] = append(wecCreated[l.Cluster], delivered)
+            if statusKinds[l.Kind] {
+                awaitingStatus = append(awaitingStatus, [2]time.Time{delivered, status})
+            }
+        }
+        if okStatus {
+            statuses = append(statuses, status)
+        }
+        // Many objects may share one ManifestWork; count it once.
+        if t, ok := l.Time(ManifestWorkCreated); ok && !works[l.ManifestWork] {
+            works[l.ManifestWork] = true
+            workCreated = append(workCreated, t)
+        }
+    }
+
+    var first, last time.Time
+    for _, times := range append([][]time.Time{wdsCreated, workCreated, statuses}, mapValues(wecCreated)...) {
+        for _, t := range times {
+            if first.IsZero() || t.Before(first) {
+                first = t
+            }
+            if t.After(last) {
+                last = t
+            }
+        }
+    }
+    if first.IsZero() {
+        return Throughput{Interval: ThroughputIntervals[0]}
+    }
+
+    interval := throughputInterval(first, last)
+    tp := Throughput{Start: first.Truncate(interval), Interval: interval}
+    buckets := int(last.Sub(tp.Start)/interval) + 1
+
+    rate := func(name string, times []time.Time) TimeSeries {
+        s := TimeSeries{Name: name, Unit: UnitRate, Values: make([]float64, buckets)}
+        for _, t := range times {
+            s.Values[int(t.Sub(tp.Start)/interval)]++
+        }
+        for i := range s.Values {
+            s.Values[i] /= interval.Seconds()
+        }
+        return s
+    }
+    // backlog counts the spans that have begun but not ended by the end of
+    // each bucket, sweeping once over the sorted starts and ends. A span
+    // without an end never leaves the backlog; one that ends before it
+    // starts, through clock skew, never enters it.
+    backlog := func(name string, spans [][2]time.Time) TimeSeries {
+        s := TimeSeries{Name: name, Unit: UnitBacklog, Values: make([]float64, buckets)}
+        var starts, ends []time.Time
+        for _, span := range spans {
+            starts = append(starts, span[0])
+            if !span[1].IsZero() {
+                ends = append(ends, maxTime(span[0], span[1]))
+            }
+        }
+        sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+        sort.Slice(ends, func(i, j int) bool { return ends[i].Before(ends[j]) })
+
+        started, ended := 0, 0
+        for i := range s.Values {
+            end := tp.Start.Add(time.Duration(i+1) * interval)
+            for started < len(starts) && starts[started].Before(end) {
+                started++
+            }
+            for ended < len(ends) && ends[ended].Before(end) {
+                ended++
+            }
+            s.Values[i] = float64(started - ended)
+        }
+        return s
+    }
+
+    tp.Series = append(tp.Series,
+        rate("created on WDS", wdsCreated),
+        rate("ManifestWorks created on ITS", workCreated),
+    )
+    clusters := make([]string, 0, len(wecCreated))
+    for c := range wecCreated {
+        clusters = append(clusters, c)
+    }
+    sort.Strings(clusters)
+    for _, c := range clusters {
+        tp.Series = append(tp.Series, rate("created on "+clusterName(c), wecCreated[c]))
+    }
+    tp.Series = append(tp.Series,
+        rate("status on WDS", statuses),
+        backlog("in flight to WEC", inFlight),
+        backlog("awaiting status", awaitingStatus),
+    )
+    return tp
+}
+
+// WriteThroughput writes tp to throughput.json and, one row per bucket and
+// one column per series, throughput.csv in dir.
+func WriteThroughput(dir string, tp Throughput) error {
+    if err := os.MkdirAll(dir, 0755); err != nil {
+        return err
+    }
+    data, err := json.MarshalIndent(tp, "", "  ")
+    if err != nil {
+        return err
+    }
+    if err := os.WriteFile(filepath.Join(dir, "throughput.json"), append(data, '\n'), 0644); err != nil {
+        return err
+    }
+
+    f, err := os.Create(filepath.Join(dir, "throughput.csv"))
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+
+    w := csv.NewWriter(f)
+    header := []string{"time", "seconds"}
+    for _, s := range tp.Series {
+        header = append(header, fmt.Sprintf("%s (%s)", s.Name, s.Unit))
+    }
+    w.Write(header)
+    for i := 0; len(tp.Series) > 0 && i < len(tp.Series[0].Values); i++ {
+        offset := time.Duration(i) * tp.Interval
+        row := []string{tp.Start.Add(offset).UTC().Format(time.RFC3339), strconv.FormatFloat(offset.Seconds(), 'f', -1, 64)}
+        for _, s := range tp.Series {
+            row = append(row, strconv.FormatFloat(s.Values[i], 'f', -1, 64))
+        }
+        w.Write(row)
+    }
+    w.Flush()
+    if err := w.Error(); err != nil {
+        return err
+    }
+    return f.Close()
+}
+
+// clusterName names a WEC in series names; objects whose cluster is not
+// known are counted under "WEC".
+func clusterName(c string) string {
+    if c == "" {
+        return "WEC"
+    }
+    return c
+}
+
+func maxTime(a, b time.Time) time.Time {
+    if a.After(b) {
+        return a
+    }
+    return b
+}
+
+func mapValues(m map[string][]time.Time) [][]time.Time {
+    out := make([][]time.Time, 0, len(m))
+    for _, v := range m {
+        out = append(out, v)
+    }
+    return out
+}
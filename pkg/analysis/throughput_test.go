@@ -0,0 +1,116 @@
+package analysis
+
+import (
+    "math/rand"
+    "testing"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/collector"
+)
+
+func series(t *testing.T, tp Throughput, name string) TimeSeries {
+    t.Helper()
+    for _, s := range tp.Series {
+        if s.Name == name {
+            return s
+        }
+    }
+    t.Fatalf("no series %q", name)
+    return TimeSeries{}
+}
+
+func wdsCreated(kind string) Event { return Event{collector.RoleWDS, kind, collector.StageCreated} }
+func wecCreated(kind string) Event { return Event{collector.RoleWEC, kind, collector.StageCreated} }
+
+func TestThroughputBacklog(t *testing.T) {
+    start := time.Date(2025, 5, 26, 15, 0, 0, 0, time.UTC)
+    rng := rand.New(rand.NewSource(1))
+    kinds := []string{"deployments", "configmaps", "secrets"}
+    var lifecycles []Lifecycle
+    for i := 0; i < 200; i++ {
+        kind := kinds[i/4%len(kinds)]
+        created := start.Add(time.Duration(rng.Int63n(int64(20 * time.Second))))
+        l := Lifecycle{Kind: kind, Cluster: "cluster1", Events: map[Event]time.Time{wdsCreated(kind): created}}
+        switch i % 4 {
+        case 0:
+            // Never delivered.
+        case 1:
+            // Delivered "before" creation through clock skew.
+            l.Events[wecCreated(kind)] = created.Add(-time.Second)
+        default:
+            l.Events[wecCreated(kind)] = created.Add(time.Duration(rng.Int63n(int64(10 * time.Second))))
+        }
+        lifecycles = append(lifecycles, l)
+    }
+
+    tp := ComputeThroughput(lifecycles)
+    if tp.Interval != time.Second {
+        t.Fatalf("interval = %v, want 1s", tp.Interval)
+    }
+    got := series(t, tp, "in flight to WEC")
+    for i, v := range got.Values {
+        end := tp.Start.Add(time.Duration(i+1) * tp.Interval)
+        want := 0.0
+        for _, l := range lifecycles {
+            created, delivered := l.Events[wdsCreated(l.Kind)], l.Events[wecCreated(l.Kind)]
+            if created.Before(end) && (delivered.IsZero() || !delivered.Before(end)) {
+                want++
+            }
+        }
+        if v != want {
+            t.Errorf("bucket %d: backlog %v, want %v", i, v, want)
+        }
+    }
+}
+
+func TestThroughputCountsEveryKind(t *testing.T) {
+    start := time.Date(2025, 5, 26, 15, 0, 0, 0, time.UTC)
+    var lifecycles []Lifecycle
+    for _, kind := range []string{"deployments", "configmaps", "secrets"} {
+        lifecycles = append(lifecycles, Lifecycle{Kind: kind, Cluster: "cluster1", Events: map[Event]time.Time{
+            wdsCreated(kind): start,
+            wecCreated(kind): start.Add(time.Second),
+        }})
+    }
+
+    tp := ComputeThroughput(lifecycles)
+    if created := series(t, tp, "created on WDS"); created.Values[0] != 3 {
+        t.Errorf("created on WDS = %v, want 3 in the first bucket", created.Values)
+    }
+    if delivered := series(t, tp, "created on cluster1"); delivered.Values[1] != 3 {
+        t.Errorf("created on cluster1 = %v, want 3 in the second bucket", delivered.Values)
+    }
+    // Only the deployment waits for a status to come back.
+    if awaiting := series(t, tp, "awaiting status"); awaiting.Values[1] != 1 {
+        t.Errorf("awaiting status = %v, want 1 in the second bucket", awaiting.Values)
+    }
+}
+
+func TestThroughputInterval(t *testing.T) {
+    start := time.Date(2025, 5, 26, 15, 0, 0, 0, time.UTC)
+    for _, tc := range []struct {
+        span     time.Duration
+        interval time.Duration
+    }{
+        {30 * time.Second, time.Second},
+        {999 * time.Second, time.Second},
+        {1000 * time.Second, 2 * time.Second},
+        {time.Hour, 5 * time.Second},
+        // A status written three days after creation.
+        {72 * time.Hour, 5 * time.Minute},
+        {5000 * 24 * time.Hour, 24 * time.Hour},
+    } {
+        l := Lifecycle{Kind: "deployments", Events: map[Event]time.Time{WDSCreated: start, WDSStatus: start.Add(tc.span)}}
+        tp := ComputeThroughput([]Lifecycle{l})
+        if tp.Interval != tc.interval {
+            t.Errorf("span %v: interval %v, want %v", tc.span, tp.Interval, tc.interval)
+        }
+        if n := len(tp.Series[0].Values); n > MaxThroughputBuckets && tc.interval != 24*time.Hour {
+            t.Errorf("span %v: %d buckets, want at most %d", tc.span, n, MaxThroughputBuckets)
+        }
+        created := series(t, tp, "created on WDS")
+        if total := created.Values[0] * tp.Interval.Seconds(); total != 1 {
+            t.Errorf("span %v: %v objects created in the first bucket, want 1", tc.span, total)
+        }
+    }
+}
@@ -2,55 +2,107 @@ package collector
 
 import (
     "context"
+    "encoding/json"
     "fmt"
+    "sort"
     "time"
 
+    "k8s.io/client-go/dynamic"
     "k8s.io/client-go/kubernetes"
+    "k8s.io/client-go/rest"
     "k8s.io/client-go/tools/clientcmd"
+    "k8s.io/client-go/util/flowcontrol"
     appsv1 "k8s.io/api/apps/v1"
     corev1 "k8s.io/api/core/v1"
     metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+// Options tunes the API clients a Collector talks to its cluster with.
+type Options struct {
+    // QPS and Burst configure the client-side rate limiter shared by the
+    // typed and dynamic clients. Zero or less keeps the client-go defaults.
+    QPS   float32
+    Burst int
+
+    // Timeout bounds each API call attempt. Transient failures (429, 5xx,
+    // timeouts, connection resets) are retried up to Retries times with
+    // exponential backoff starting at RetryBackoff.
+    Timeout      time.Duration
+    Retries      int
+    RetryBackoff time.Duration
+}
+
 type Collector struct {
     Clientset *kubernetes.Clientset
+    Dynamic   dynamic.Interface
     Context   string
+    // Role is stamped on every record this collector returns.
+    Role Role
+
+    opts    Options
+    limiter *throttleRecorder
 }
 
 func parseServiceMetrics(svc corev1.Service) ObjectMetrics {
-    return ObjectMetrics{
-        Name:         svc.Name,
-        Namespace:    svc.Namespace,
-        Created:      svc.CreationTimestamp.Format(time.RFC3339),
-        StatusUpdate: getStatusTime(svc.ObjectMeta),
-        Condition:    "Active",  // Add proper status detection
-        Manager:      getManager(svc.ObjectMeta),
-    }
+    m := objectMetrics(svc.ObjectMeta)
+    m.StatusUpdate = getStatusTime(svc.ObjectMeta)
+    svc.Status = corev1.ServiceStatus{}
+    m.Size = objectSize(&svc, &svc.ObjectMeta)
+    m.Condition = "Active"  // Add proper status detection
+    return withStages(m)
 }
 
 func parseSecretMetrics(secret corev1.Secret) ObjectMetrics {
-    return ObjectMetrics{
-        Name:         secret.Name,
-        Namespace:    secret.Namespace,
-        Created:      secret.CreationTimestamp.Format(time.RFC3339),
-        StatusUpdate: "",  // Secrets typically don't have status
-        Condition:    "Exists",
-        Manager:      getManager(secret.ObjectMeta),
-    }
+    m := objectMetrics(secret.ObjectMeta)
+    m.Size = objectSize(&secret, &secret.ObjectMeta)
+    m.Condition = "Exists"  // Secrets typically don't have status
+    return withStages(m)
 }
 
 func parseConfigMapMetrics(cm corev1.ConfigMap) ObjectMetrics {
+    m := objectMetrics(cm.ObjectMeta)
+    m.Size = objectSize(&cm, &cm.ObjectMeta)
+    m.Condition = "Exists"  // ConfigMaps typically don't have status
+    return withStages(m)
+}
+
+// objectSize is the length of obj's JSON encoding without the managedFields
+// in meta, which must belong to obj. Callers clear the status beforehand.
+func objectSize(obj interface{}, meta *metav1.ObjectMeta) int {
+    meta.ManagedFields = nil
+    data, err := json.Marshal(obj)
+    if err != nil {
+        return 0
+    }
+    return len(data)
+}
+
+// objectMetrics fills the fields every kind shares from its metadata.
+func objectMetrics(meta metav1.ObjectMeta) ObjectMetrics {
     return ObjectMetrics{
-        Name:         cm.Name,
-        Namespace:    cm.Namespace,
-        Created:      cm.CreationTimestamp.Format(time.RFC3339),
-        StatusUpdate: "",  // ConfigMaps typically don't have status
-        Condition:    "Exists",
-        Manager:      getManager(cm.ObjectMeta),
+        Name:      meta.Name,
+        Namespace: meta.Namespace,
+        UID:       string(meta.UID),
+        Created:   meta.CreationTimestamp.Time,
+        Manager:   getManager(meta),
+        Stages:    map[string]time.Time{},
+        Writers:   getWriters(&meta),
     }
 }
 
-func NewCollector(kubeconfig, contextName string) (*Collector, error) {
+// withStages records the typed timestamps of m under their stage keys.
+func withStages(m ObjectMetrics) ObjectMetrics {
+    if !m.Created.IsZero() {
+        m.Stages[StageCreated] = m.Created
+    }
+    if m.StatusUpdate != nil {
+        m.Stages[StageStatus] = *m.StatusUpdate
+    }
+    return m
+}
+
+func NewCollector(kubeconfig, contextName string, opts Options) (*Collector, error) {
     config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
         &clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
         &clientcmd.ConfigOverrides{CurrentContext: contextName},
@@ -59,81 +111,183 @@ func NewCollector(kubeconfig, contextName string) (*Collector, error) {
         return nil, fmt.Errorf("failed to create client config: %v", err)
     }
 
+    if opts.Timeout <= 0 {
+        opts.Timeout = defaultTimeout
+    }
+    if opts.RetryBackoff <= 0 {
+        opts.RetryBackoff = defaultRetryBackoff
+    }
+
+    // A kubeconfig leaves both at zero, which would build a limiter that
+    // rejects every request.
+    if opts.QPS > 0 {
+        config.QPS = opts.QPS
+    } else if config.QPS <= 0 {
+        config.QPS = rest.DefaultQPS
+    }
+    if opts.Burst > 0 {
+        config.Burst = opts.Burst
+    } else if config.Burst <= 0 {
+        config.Burst = rest.DefaultBurst
+    }
+    // One limiter for both clients, wrapped so time spent waiting on it can
+    // be reported separately from API server latency.
+    limiter := newThrottleRecorder(flowcontrol.NewTokenBucketRateLimiter(config.QPS, config.Burst))
+    config.RateLimiter = limiter
+
     clientset, err := kubernetes.NewForConfig(config)
     if err != nil {
         return nil, fmt.Errorf("failed to create clientset: %v", err)
     }
 
+    dynClient, err := dynamic.NewForConfig(config)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create dynamic client: %v", err)
+    }
+
     return &Collector{
         Clientset: clientset,
+        Dynamic:   dynClient,
         Context:   contextName,
+        opts:      opts,
+        limiter:   limiter,
     }, nil
 }
 
-func (c *Collector) CollectStandardObjects(kind, namespace string) ([]ObjectMetrics, error) {
+// ThrottleStats reports how long requests from this collector spent waiting
+// on the client-side rate limiter.
+func (c *Collector) ThrottleStats() ThrottleStats {
+    return c.limiter.stats()
+}
+
+// standardGVRs lists the built-in kinds CollectStandardObjects understands.
+var standardGVRs = map[string]schema.GroupVersionResource{
+    "deployments": {Group: "apps", Version: "v1", Resource: "deployments"},
+    "services":    {Version: "v1", Resource: "services"},
+    "secrets":     {Version: "v1", Resource: "secrets"},
+    "configmaps":  {Version: "v1", Resource: "configmaps"},
+}
+
+// StandardGVR returns the resource behind a kind collected by
+// CollectStandardObjects.
+func StandardGVR(kind string) (schema.GroupVersionResource, bool) {
+    gvr, ok := standardGVRs[kind]
+    return gvr, ok
+}
+
+func (c *Collector) CollectStandardObjects(ctx context.Context, kind, namespace string) ([]ObjectMetrics, error) {
+    if _, ok := standardGVRs[kind]; !ok {
+        return nil, fmt.Errorf("unsupported kind %q", kind)
+    }
+
     var metrics []ObjectMetrics
 
-    switch kind {
-    case "deployments":
-        deps, err := c.Clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
-        if err != nil {
-            return nil, err
-        }
-        for _, dep := range deps.Items {
-            metrics = append(metrics, parseDeploymentMetrics(dep))
-        }
-    case "services":
-        svcs, err := c.Clientset.CoreV1().Services(namespace).List(context.TODO(), metav1.ListOptions{})
-        if err != nil {
-            return nil, err
-        }
-        for _, svc := range svcs.Items {
-            metrics = append(metrics, parseServiceMetrics(svc))
-        }
-    case "secrets":
-        secrets, err := c.Clientset.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{})
-        if err != nil {
-            return nil, err
-        }
-        for _, secret := range secrets.Items {
-            metrics = append(metrics, parseSecretMetrics(secret))
-        }
-    case "configmaps":
-        cms, err := c.Clientset.CoreV1().ConfigMaps(namespace).List(context.TODO(), metav1.ListOptions{})
-        if err != nil {
-            return nil, err
-        }
-        for _, cm := range cms.Items {
-            metrics = append(metrics, parseConfigMapMetrics(cm))
+    err := c.call(ctx, func(ctx context.Context) error {
+        metrics = nil
+        switch kind {
+        case "deployments":
+            deps, err := c.Clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+            if err != nil {
+                return err
+            }
+            for _, dep := range deps.Items {
+                metrics = append(metrics, parseDeploymentMetrics(dep))
+            }
+        case "services":
+            svcs, err := c.Clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+            if err != nil {
+                return err
+            }
+            for _, svc := range svcs.Items {
+                metrics = append(metrics, parseServiceMetrics(svc))
+            }
+        case "secrets":
+            secrets, err := c.Clientset.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{})
+            if err != nil {
+                return err
+            }
+            for _, secret := range secrets.Items {
+                metrics = append(metrics, parseSecretMetrics(secret))
+            }
+        case "configmaps":
+            cms, err := c.Clientset.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{})
+            if err != nil {
+                return err
+            }
+            for _, cm := range cms.Items {
+                metrics = append(metrics, parseConfigMapMetrics(cm))
+            }
         }
+        return nil
+    })
+    if err != nil {
+        return nil, err
     }
 
+    for i := range metrics {
+        metrics[i].Cluster = c.Context
+        metrics[i].Role = c.Role
+        metrics[i].Kind = kind
+    }
     return metrics, nil
 }
 
 func parseDeploymentMetrics(dep appsv1.Deployment) ObjectMetrics {
     condition := "Unavailable"
-    if dep.Status.ReadyReplicas == *dep.Spec.Replicas {
+    if dep.Spec.Replicas != nil && dep.Status.ReadyReplicas == *dep.Spec.Replicas {
         condition = "Available"
     }
-    
-    return ObjectMetrics{
-        Name:         dep.Name,
-        Namespace:    dep.Namespace,
-        Created:      dep.CreationTimestamp.Format(time.RFC3339),
-        StatusUpdate: getStatusTime(dep.ObjectMeta),
-        Condition:    condition,
-        Manager:      getManager(dep.ObjectMeta),
+
+    m := objectMetrics(dep.ObjectMeta)
+    m.StatusUpdate = getStatusTime(dep.ObjectMeta)
+    m.Condition = condition
+    m = withStages(m)
+
+    for _, c := range dep.Status.Conditions {
+        if c.Type == appsv1.DeploymentAvailable && c.Status == corev1.ConditionTrue && !c.LastTransitionTime.IsZero() {
+            m.Stages[StageAvailable] = c.LastTransitionTime.Time
+        }
     }
+
+    dep.Status = appsv1.DeploymentStatus{}
+    m.Size = objectSize(&dep, &dep.ObjectMeta)
+    return m
 }
 
-func getStatusTime(meta metav1.ObjectMeta) string {
+func getStatusTime(meta metav1.ObjectMeta) *time.Time {
     for _, mf := range meta.ManagedFields {
-        if mf.Operation == "Update" && mf.Subresource == "status" {
-            return mf.Time.Format(time.RFC3339)
+        if mf.Operation == "Update" && mf.Subresource == "status" && mf.Time != nil {
+            t := mf.Time.Time
+            return &t
         }
     }
-    return ""
+    return nil
+}
+
+// getLastUpdate returns the most recent write recorded in managedFields.
+func getLastUpdate(meta metav1.Object) *time.Time {
+    var latest *time.Time
+    for _, mf := range meta.GetManagedFields() {
+        if mf.Time != nil && (latest == nil || mf.Time.After(*latest)) {
+            t := mf.Time.Time
+            latest = &t
+        }
+    }
+    return latest
+}
+
+// getWriters lists the managedFields entries of meta, oldest first.
+func getWriters(meta metav1.Object) []Writer {
+    var writers []Writer
+    for _, mf := range meta.GetManagedFields() {
+        w := Writer{Manager: mf.Manager, Operation: string(mf.Operation), Subresource: mf.Subresource}
+        if mf.Time != nil {
+            w.Time = mf.Time.Time
+        }
+        writers = append(writers, w)
+    }
+    sort.SliceStable(writers, func(i, j int) bool { return writers[i].Time.Before(writers[j].Time) })
+    return writers
 }
 
 func getManager(meta metav1.ObjectMeta) string {
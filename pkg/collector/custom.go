@@ -7,8 +7,6 @@ import (
 
     "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
     "k8s.io/apimachinery/pkg/runtime/schema"
-    "k8s.io/client-go/dynamic"
-    "k8s.io/client-go/tools/clientcmd"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -24,32 +22,50 @@ func findTargetObject(item unstructured.Unstructured) string {
     return ""
 }
 
-func (c *Collector) CollectCustomResources(gvr schema.GroupVersionResource, namespace, labelSelector string) ([]WorkMetrics, error) {
-    dynClient, err := getDynamicClient(c.Context)
-    if err != nil {
-        return nil, err
-    }
-
-    list, err := dynClient.Resource(gvr).Namespace(namespace).List(
-        context.TODO(), 
-        metav1.ListOptions{
-            LabelSelector: labelSelector,
-        },
-    )
+func (c *Collector) CollectCustomResources(ctx context.Context, gvr schema.GroupVersionResource, namespace, labelSelector string) ([]WorkMetrics, error) {
+    var list *unstructured.UnstructuredList
+    err := c.call(ctx, func(ctx context.Context) error {
+        var err error
+        list, err = c.Dynamic.Resource(gvr).Namespace(namespace).List(
+            ctx,
+            metav1.ListOptions{
+                LabelSelector: labelSelector,
+            },
+        )
+        return err
+    })
     if err != nil {
         return nil, err
     }
 
     var metrics []WorkMetrics
     for _, item := range list.Items {
-        metrics = append(metrics, parseWorkMetrics(item, gvr))
+        m := parseWorkMetrics(item, gvr)
+        m.Cluster = c.Context
+        m.Role = c.Role
+        metrics = append(metrics, m)
     }
     return metrics, nil
 }
 
+// CreationTimestamp returns when a single object of gvr was created.
+func (c *Collector) CreationTimestamp(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (time.Time, error) {
+    var obj *unstructured.Unstructured
+    err := c.call(ctx, func(ctx context.Context) error {
+        var err error
+        obj, err = c.Dynamic.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+        return err
+    })
+    if err != nil {
+        return time.Time{}, err
+    }
+    return obj.GetCreationTimestamp().Time, nil
+}
+
 func parseWorkMetrics(item unstructured.Unstructured, gvr schema.GroupVersionResource) WorkMetrics {
     status, _, _ := unstructured.NestedString(item.Object, "status", "phase")
     var targetObj string
+    var targets []string
     
     switch gvr.Resource {
     case "manifestworks":
@@ -57,8 +73,10 @@ func parseWorkMetrics(item unstructured.Unstructured, gvr schema.GroupVersionRes
             for _, m := range manifests {
                 if manifest, ok := m.(map[string]interface{}); ok {
                     if name, _, _ := unstructured.NestedString(manifest, "metadata", "name"); name != "" {
-                        targetObj = name
-                        break
+                        if targetObj == "" {
+                            targetObj = name
+                        }
+                        targets = append(targets, name)
                     }
                 }
             }
@@ -67,23 +85,24 @@ func parseWorkMetrics(item unstructured.Unstructured, gvr schema.GroupVersionRes
         targetObj = strings.TrimPrefix(item.GetName(), "v1-pod-")
     }
 
-    return WorkMetrics{
+    m := WorkMetrics{
+        Kind:         gvr.Resource,
+        Binding:      item.GetLabels()[BindingKeyLabel],
         Name:         item.GetName(),
         Namespace:    item.GetNamespace(),
-        Created:      item.GetCreationTimestamp().Format(time.RFC3339),
+        UID:          string(item.GetUID()),
+        Created:      item.GetCreationTimestamp().Time,
+        Updated:      getLastUpdate(&item),
         Status:       status,
         TargetObject: targetObj,
+        Stages:       map[string]time.Time{},
+        Writers:      getWriters(&item),
     }
-}
-
-func getDynamicClient(contextName string) (dynamic.Interface, error) {
-    config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-        clientcmd.NewDefaultClientConfigLoadingRules(),
-        &clientcmd.ConfigOverrides{CurrentContext: contextName},
-    ).ClientConfig()
-    if err != nil {
-        return nil, err
+    if !m.Created.IsZero() {
+        m.Stages[StageCreated] = m.Created
     }
-
-    return dynamic.NewForConfig(config)
-}
\ No newline at end of file
+    if m.Updated != nil {
+        m.Stages[StageUpdated] = *m.Updated
+    }
+    return m
+}
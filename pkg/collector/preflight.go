@@ -0,0 +1,191 @@
+package collector
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "strings"
+
+    authorizationv1 "k8s.io/api/authorization/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// CheckResult is the outcome of a single preflight check against a cluster.
+type CheckResult struct {
+    Context string
+    Role    Role
+    Check   string
+    Target  string
+    Passed  bool
+    Detail  string
+}
+
+// Preflight verifies that the cluster is reachable, that every resource in
+// reqs for the given role is served, and that each verb on it is allowed for
+// the current user.
+func (c *Collector) Preflight(ctx context.Context, role Role, reqs []Requirement) []CheckResult {
+    result := func(check, target string, err error) CheckResult {
+        r := CheckResult{Context: c.Context, Role: role, Check: check, Target: target, Passed: err == nil}
+        if err != nil {
+            r.Detail = err.Error()
+        }
+        return r
+    }
+
+    version, err := c.serverVersion(ctx)
+    if err != nil {
+        return []CheckResult{result("reachable", c.Context, err)}
+    }
+    reachable := result("reachable", c.Context, nil)
+    reachable.Detail = version
+    results := []CheckResult{reachable}
+
+    for _, req := range reqs {
+        if req.Role != role {
+            continue
+        }
+        target := gvrString(req.GVR)
+
+        if err := c.checkResource(ctx, req.GVR); err != nil {
+            results = append(results, result("resource", target, err))
+            continue
+        }
+        results = append(results, result("resource", target, nil))
+
+        for _, verb := range req.Verbs {
+            results = append(results, result("access", verb+" "+target, c.checkAccess(ctx, req, verb)))
+        }
+    }
+    return results
+}
+
+func (c *Collector) serverVersion(ctx context.Context) (string, error) {
+    var info struct {
+        GitVersion string `json:"gitVersion"`
+    }
+    err := c.call(ctx, func(ctx context.Context) error {
+        raw, err := c.Clientset.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Raw()
+        if err != nil {
+            return err
+        }
+        return json.Unmarshal(raw, &info)
+    })
+    return info.GitVersion, err
+}
+
+// checkResource confirms through discovery that gvr is served.
+func (c *Collector) checkResource(ctx context.Context, gvr schema.GroupVersionResource) error {
+    path := "/apis/" + gvr.Group + "/" + gvr.Version
+    if gvr.Group == "" {
+        path = "/api/" + gvr.Version
+    }
+
+    var list metav1.APIResourceList
+    err := c.call(ctx, func(ctx context.Context) error {
+        raw, err := c.Clientset.Discovery().RESTClient().Get().AbsPath(path).Do(ctx).Raw()
+        if err != nil {
+            return err
+        }
+        return json.Unmarshal(raw, &list)
+    })
+    if apierrors.IsNotFound(err) {
+        return fmt.Errorf("group version %s is not served; is the CRD installed?", gvr.GroupVersion())
+    }
+    if err != nil {
+        return err
+    }
+
+    for _, r := range list.APIResources {
+        if r.Name == gvr.Resource {
+            return nil
+        }
+    }
+    return fmt.Errorf("resource %s not found in %s; is the CRD installed?", gvr.Resource, gvr.GroupVersion())
+}
+
+// checkAccess asks the API server whether the current user may perform verb
+// on req, on each of its named objects if it names any.
+func (c *Collector) checkAccess(ctx context.Context, req Requirement, verb string) error {
+    if len(req.Names) == 0 {
+        return c.checkNamedAccess(ctx, req, verb, "")
+    }
+    for _, name := range req.Names {
+        if err := c.checkNamedAccess(ctx, req, verb, name); err != nil {
+            return fmt.Errorf("%s: %v", name, err)
+        }
+    }
+    return nil
+}
+
+// checkNamedAccess asks whether the current user may perform verb on the
+// object called name of req, or on all of them when name is empty. A
+// cluster-wide grant covers every namespace; otherwise each namespace is
+// checked on its own.
+func (c *Collector) checkNamedAccess(ctx context.Context, req Requirement, verb, name string) error {
+    allowed, reason, err := c.accessReview(ctx, req.GVR, "", name, verb)
+    if err != nil {
+        return err
+    }
+    if allowed {
+        return nil
+    }
+    if len(req.Namespaces) == 0 {
+        return fmt.Errorf("denied cluster-wide%s", reason)
+    }
+
+    var denied []string
+    for _, ns := range req.Namespaces {
+        allowed, _, err := c.accessReview(ctx, req.GVR, ns, name, verb)
+        if err != nil {
+            return err
+        }
+        if !allowed {
+            denied = append(denied, ns)
+        }
+    }
+    if len(denied) > 0 {
+        return fmt.Errorf("denied in %s%s", strings.Join(denied, ", "), reason)
+    }
+    return nil
+}
+
+func (c *Collector) accessReview(ctx context.Context, gvr schema.GroupVersionResource, namespace, name, verb string) (bool, string, error) {
+    review := &authorizationv1.SelfSubjectAccessReview{
+        Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+            ResourceAttributes: &authorizationv1.ResourceAttributes{
+                Namespace: namespace,
+                Name:      name,
+                Verb:      verb,
+                Group:     gvr.Group,
+                Version:   gvr.Version,
+                Resource:  gvr.Resource,
+            },
+        },
+    }
+
+    var resp *authorizationv1.SelfSubjectAccessReview
+    err := c.call(ctx, func(ctx context.Context) error {
+        var err error
+        resp, err = c.Clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+        return err
+    })
+    if err != nil {
+        return false, "", err
+    }
+
+    reason := ""
+    if resp.Status.Reason != "" {
+        reason = ": " + resp.Status.Reason
+    }
+    return resp.Status.Allowed, reason, nil
+}
+
+// gvrString renders gvr as resource.group/version, the form kubectl uses.
+func gvrString(gvr schema.GroupVersionResource) string {
+    if gvr.Group == "" {
+        return gvr.Resource + "/" + gvr.Version
+    }
+    return gvr.Resource + "." + gvr.Group + "/" + gvr.Version
+}
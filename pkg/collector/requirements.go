@@ -0,0 +1,108 @@
+package collector
+
+import (
+    "fmt"
+
+    "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Role is the part a cluster plays in a KubeStellar deployment.
+type Role string
+
+const (
+    RoleWDS Role = "wds"
+    RoleITS Role = "its"
+    RoleWEC Role = "wec"
+)
+
+// StandardKinds are the workload kinds collected from both the WDS and the WEC.
+var StandardKinds = []string{"deployments", "secrets", "configmaps", "services"}
+
+var (
+    ManifestWorkGVR = schema.GroupVersionResource{
+        Group:    "work.open-cluster-management.io",
+        Version:  "v1",
+        Resource: "manifestworks",
+    }
+    WorkStatusGVR = schema.GroupVersionResource{
+        Group:    "control.kubestellar.io",
+        Version:  "v1alpha1",
+        Resource: "workstatuses",
+    }
+    AppliedManifestWorkGVR = schema.GroupVersionResource{
+        Group:    "work.open-cluster-management.io",
+        Version:  "v1",
+        Resource: "appliedmanifestworks",
+    }
+    BindingPolicyGVR = schema.GroupVersionResource{
+        Group:    "control.kubestellar.io",
+        Version:  "v1alpha1",
+        Resource: "bindingpolicies",
+    }
+)
+
+// BindingPolicyName is the BindingPolicy whose creation starts the
+// experiment's clock.
+const BindingPolicyName = "nginx-bpolicy"
+
+// BindingKeyLabel marks transport objects with the binding that produced
+// them; in these experiments every namespace has its own binding.
+const BindingKeyLabel = "transport.kubestellar.io/originOwnerReferenceBindingKey"
+
+// ExperimentNamespace is the namespace clusterloader2 creates for index i.
+func ExperimentNamespace(i int) string {
+    return fmt.Sprintf("perf-test-%d", i)
+}
+
+// Requirement is one resource the collector reads from a cluster. An empty
+// Namespaces list means the resource is read cluster-wide; an empty Names
+// list means every object of it is.
+type Requirement struct {
+    Role       Role
+    GVR        schema.GroupVersionResource
+    Namespaces []string
+    Names      []string
+    Verbs      []string
+}
+
+// Requirements lists every resource a run with args reads or writes, per
+// cluster role.
+func Requirements(args CollectionArgs) []Requirement {
+    namespaces := make([]string, 0, args.NumNS)
+    for i := 0; i < args.NumNS; i++ {
+        namespaces = append(namespaces, ExperimentNamespace(i))
+    }
+
+    var reqs []Requirement
+    for _, role := range []Role{RoleWDS, RoleWEC} {
+        for _, kind := range StandardKinds {
+            reqs = append(reqs, Requirement{
+                Role:       role,
+                GVR:        standardGVRs[kind],
+                Namespaces: namespaces,
+                Verbs:      []string{"list"},
+            })
+        }
+    }
+
+    reqs = append(reqs,
+        Requirement{Role: RoleWDS, GVR: BindingPolicyGVR, Names: []string{BindingPolicyName}, Verbs: []string{"get"}},
+        // ManifestWorks and WorkStatuses live in the ITS namespace named after the WEC.
+        Requirement{Role: RoleITS, GVR: ManifestWorkGVR, Namespaces: []string{args.WECContext}, Verbs: []string{"list"}},
+        Requirement{Role: RoleITS, GVR: WorkStatusGVR, Namespaces: []string{args.WECContext}, Verbs: []string{"list"}},
+        Requirement{Role: RoleWEC, GVR: AppliedManifestWorkGVR, Verbs: []string{"list"}},
+    )
+
+    // The clock probe is the only write the collector makes.
+    if args.ClockProbes > 0 {
+        for _, role := range []Role{RoleWDS, RoleITS, RoleWEC} {
+            reqs = append(reqs, Requirement{
+                Role:       role,
+                GVR:        ClockProbeGVR,
+                Namespaces: []string{args.ClockNamespace},
+                Verbs:      []string{"create", "delete"},
+            })
+        }
+    }
+    return reqs
+}
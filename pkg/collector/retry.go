@@ -0,0 +1,73 @@
+package collector
+
+import (
+    "context"
+    "errors"
+    "net"
+    "time"
+
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    utilnet "k8s.io/apimachinery/pkg/util/net"
+    "k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+    defaultTimeout      = 30 * time.Second
+    defaultRetryBackoff = 500 * time.Millisecond
+)
+
+// call runs fn with a per-attempt timeout, retrying transient failures with
+// exponential backoff until the retries are used up or ctx is done.
+func (c *Collector) call(ctx context.Context, fn func(ctx context.Context) error) error {
+    backoff := wait.Backoff{
+        Duration: c.opts.RetryBackoff,
+        Factor:   2,
+        Jitter:   0.2,
+        Steps:    c.opts.Retries,
+        Cap:      30 * time.Second,
+    }
+
+    for {
+        callCtx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+        err := fn(callCtx)
+        cancel()
+
+        if err == nil || ctx.Err() != nil || !isTransient(err) || backoff.Steps <= 0 {
+            return err
+        }
+
+        select {
+        case <-ctx.Done():
+            return err
+        case <-time.After(backoff.Step()):
+        }
+    }
+}
+
+// isTransient reports whether err is worth retrying: throttling, server
+// side failures, timeouts and dropped connections.
+func isTransient(err error) bool {
+    switch {
+    case apierrors.IsTooManyRequests(err),
+        apierrors.IsServerTimeout(err),
+        apierrors.IsTimeout(err),
+        apierrors.IsInternalError(err),
+        apierrors.IsServiceUnavailable(err),
+        apierrors.IsUnexpectedServerError(err):
+        return true
+    case utilnet.IsConnectionReset(err),
+        utilnet.IsConnectionRefused(err),
+        utilnet.IsProbableEOF(err):
+        return true
+    case errors.Is(err, context.DeadlineExceeded):
+        return true
+    }
+
+    var status apierrors.APIStatus
+    if errors.As(err, &status) && status.Status().Code >= 500 {
+        return true
+    }
+
+    var netErr net.Error
+    return errors.As(err, &netErr) && netErr.Timeout()
+}
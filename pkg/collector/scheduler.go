@@ -0,0 +1,93 @@
+package collector
+
+import (
+    "context"
+    "fmt"
+    "sync"
+
+    "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Task is one independent unit of collection, typically a single list call
+// for a resource in a namespace on one cluster.
+type Task struct {
+    Cluster   string
+    GVR       schema.GroupVersionResource
+    Namespace string
+    Run       func(ctx context.Context) error
+}
+
+func (t Task) String() string {
+    return fmt.Sprintf("%s/%s/%s", t.Cluster, t.Namespace, t.GVR.Resource)
+}
+
+// CollectionError records a task that failed, so the rest of the run can
+// carry on and the gap can be reported afterwards.
+type CollectionError struct {
+    Cluster   string `json:"cluster"`
+    GVR       string `json:"gvr"`
+    Namespace string `json:"namespace"`
+    Error     string `json:"error"`
+}
+
+// Scheduler runs collection tasks on a bounded pool of workers.
+type Scheduler struct {
+    Workers int
+}
+
+// Run executes tasks concurrently on at most s.Workers goroutines. A failing
+// task does not stop the others; it is returned as a CollectionError along
+// with the number of tasks that completed. Cancelling ctx stops any further
+// tasks from being started.
+func (s Scheduler) Run(ctx context.Context, tasks []Task) (int, []CollectionError) {
+    workers := s.Workers
+    if workers < 1 {
+        workers = 1
+    }
+    if workers > len(tasks) {
+        workers = len(tasks)
+    }
+
+    var (
+        wg        sync.WaitGroup
+        mu        sync.Mutex
+        completed int
+        failures  []CollectionError
+        queue     = make(chan Task)
+    )
+
+    for i := 0; i < workers; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for task := range queue {
+                err := task.Run(ctx)
+                mu.Lock()
+                if err == nil {
+                    completed++
+                } else if ctx.Err() == nil {
+                    failures = append(failures, CollectionError{
+                        Cluster:   task.Cluster,
+                        GVR:       task.GVR.String(),
+                        Namespace: task.Namespace,
+                        Error:     err.Error(),
+                    })
+                }
+                mu.Unlock()
+            }
+        }()
+    }
+
+dispatch:
+    for _, task := range tasks {
+        select {
+        case <-ctx.Done():
+            break dispatch
+        case queue <- task:
+        }
+    }
+    close(queue)
+    wg.Wait()
+
+    return completed, failures
+}
@@ -0,0 +1,106 @@
+package collector
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ClockProbeGVR is the resource written to clusters to read their clocks.
+var ClockProbeGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+// ClockOffset is how far the API server clock of a cluster is ahead of the
+// local clock. The true offset lies within Offset ± Error.
+type ClockOffset struct {
+    Role    Role          `json:"role"`
+    Context string        `json:"context"`
+    Offset  time.Duration `json:"offset"`
+    Error   time.Duration `json:"error"`
+    Probes  int           `json:"probes"`
+}
+
+func (o ClockOffset) String() string {
+    return fmt.Sprintf("%s (%s): %+v ± %v over %d probes", o.Role, o.Context,
+        o.Offset.Round(time.Millisecond), o.Error.Round(time.Millisecond), o.Probes)
+}
+
+// ProbeClock estimates the offset of the API server clock by creating
+// probes short-lived ConfigMaps in namespace, NTP-style: the server stamps
+// each one between the local send and receive times. creationTimestamp only
+// has second precision, so every probe bounds the offset to an interval
+// about a second wide; spreading the probes over a second and intersecting
+// the intervals narrows it down to roughly the round trip time.
+func (c *Collector) ProbeClock(ctx context.Context, namespace string, probes int) (ClockOffset, error) {
+    if probes < 1 {
+        return ClockOffset{}, fmt.Errorf("need at least one clock probe, got %d", probes)
+    }
+    lo, hi := time.Duration(-1<<63), time.Duration(1<<63-1)
+    spacing := time.Second / time.Duration(probes)
+
+    for i := 0; i < probes; i++ {
+        if i > 0 {
+            select {
+            case <-ctx.Done():
+                return ClockOffset{}, ctx.Err()
+            // The extra millisecond keeps the probes from landing on the
+            // same fraction of a second when the spacing divides it evenly.
+            case <-time.After(spacing + time.Millisecond):
+            }
+        }
+
+        created, sent, received, err := c.createProbe(ctx, namespace)
+        if err != nil {
+            return ClockOffset{}, fmt.Errorf("clock probe in %s/%s: %v", c.Context, namespace, err)
+        }
+
+        // The server stamped the probe somewhere in [created, created+1s)
+        // of its own clock, and between sent and received of ours.
+        lo = max(lo, created.Sub(received))
+        hi = min(hi, created.Add(time.Second).Sub(sent))
+    }
+
+    if lo > hi {
+        return ClockOffset{}, fmt.Errorf("clock probes of %s disagree; did its clock step during probing?", c.Context)
+    }
+    return ClockOffset{
+        Role:    c.Role,
+        Context: c.Context,
+        Offset:  lo + (hi-lo)/2,
+        Error:   (hi - lo) / 2,
+        Probes:  probes,
+    }, nil
+}
+
+// createProbe creates and deletes one probe object, returning its
+// creationTimestamp and the local times just before and after creating it.
+// It is not retried, since a retry would widen the window it measures.
+func (c *Collector) createProbe(ctx context.Context, namespace string) (created, sent, received time.Time, err error) {
+    probe := &corev1.ConfigMap{
+        ObjectMeta: metav1.ObjectMeta{
+            GenerateName: "kubestellar-collector-clock-",
+            Labels:       map[string]string{"app.kubernetes.io/name": "kubestellar-collector"},
+        },
+    }
+
+    callCtx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+    defer cancel()
+
+    sent = time.Now()
+    probe, err = c.Clientset.CoreV1().ConfigMaps(namespace).Create(callCtx, probe, metav1.CreateOptions{})
+    received = time.Now()
+    if err != nil {
+        return time.Time{}, time.Time{}, time.Time{}, err
+    }
+
+    err = c.call(ctx, func(ctx context.Context) error {
+        return c.Clientset.CoreV1().ConfigMaps(namespace).Delete(ctx, probe.Name, metav1.DeleteOptions{})
+    })
+    if err != nil {
+        return time.Time{}, time.Time{}, time.Time{}, fmt.Errorf("deleting %s: %v", probe.Name, err)
+    }
+    return probe.CreationTimestamp.Time, sent, received, nil
+}
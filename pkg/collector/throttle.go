@@ -0,0 +1,55 @@
+package collector
+
+import (
+    "context"
+    "sync/atomic"
+    "time"
+
+    "k8s.io/client-go/util/flowcontrol"
+)
+
+// throttledAfter is the wait below which a request is not counted as
+// throttled; the token bucket itself costs a few microseconds.
+const throttledAfter = time.Millisecond
+
+// ThrottleStats summarises client-side rate limiting for one collector.
+type ThrottleStats struct {
+    Requests  int64
+    Throttled int64
+    Waited    time.Duration
+}
+
+// throttleRecorder wraps a RateLimiter and accounts for the time requests
+// spend blocked in Wait.
+type throttleRecorder struct {
+    flowcontrol.RateLimiter
+
+    requests  atomic.Int64
+    throttled atomic.Int64
+    waited    atomic.Int64
+}
+
+func newThrottleRecorder(limiter flowcontrol.RateLimiter) *throttleRecorder {
+    return &throttleRecorder{RateLimiter: limiter}
+}
+
+func (t *throttleRecorder) Wait(ctx context.Context) error {
+    start := time.Now()
+    err := t.RateLimiter.Wait(ctx)
+    waited := time.Since(start)
+
+    t.requests.Add(1)
+    if waited >= throttledAfter {
+        t.throttled.Add(1)
+        t.waited.Add(int64(waited))
+    }
+    return err
+}
+
+func (t *throttleRecorder) stats() ThrottleStats {
+    return ThrottleStats{
+        Requests:  t.requests.Load(),
+        Throttled: t.throttled.Load(),
+        Waited:    time.Duration(t.waited.Load()),
+    }
+}
@@ -1,21 +1,90 @@
 package collector
 
+import (
+    "sync"
+    "time"
+)
+
+// Well-known keys of the Stages map on collected records.
+const (
+    // StageCreated is the object's creationTimestamp.
+    StageCreated = "created"
+    // StageStatus is the first status subresource update in managedFields.
+    StageStatus = "status"
+    // StageAvailable is when a Deployment's Available condition became true.
+    StageAvailable = "available"
+    // StageUpdated is the most recent write recorded in managedFields.
+    StageUpdated = "updated"
+)
+
+// ObjectMetrics describes one workload object as listed from one cluster.
+// Optional timestamps are nil when the event has not happened (yet); Stages
+// holds every known timestamp under a well-known key.
 type ObjectMetrics struct {
-    Name          string
-    Namespace     string
-    Created       string
-    StatusUpdate  string
-    Condition     string
-    Manager       string
+    Cluster      string               `json:"cluster"`
+    Role         Role                 `json:"role"`
+    Kind         string               `json:"kind"`
+    Name         string               `json:"name"`
+    Namespace    string               `json:"namespace"`
+    UID          string               `json:"uid"`
+    Created      time.Time            `json:"created"`
+    StatusUpdate *time.Time           `json:"statusUpdate,omitempty"`
+    Condition    string               `json:"condition"`
+    Manager      string               `json:"manager,omitempty"`
+    Stages       map[string]time.Time `json:"stages"`
+    Writers      []Writer             `json:"writers,omitempty"`
+    // Size is the length in bytes of the object's JSON encoding, without
+    // managedFields and status: roughly what the transport carries.
+    Size int `json:"size,omitempty"`
 }
 
+// WorkMetrics describes one KubeStellar/OCM work object (ManifestWork,
+// WorkStatus, AppliedManifestWork) as listed from one cluster.
 type WorkMetrics struct {
-    Name         string
-    Namespace    string
-    Created      string
-    Updated      string
-    Status       string
-    TargetObject string
+    Cluster      string               `json:"cluster"`
+    Role         Role                 `json:"role"`
+    Kind         string               `json:"kind"`
+    Name         string               `json:"name"`
+    Namespace    string               `json:"namespace"`
+    UID          string               `json:"uid"`
+    Created      time.Time            `json:"created"`
+    Updated      *time.Time           `json:"updated,omitempty"`
+    Status       string               `json:"status,omitempty"`
+    TargetObject string               `json:"targetObject,omitempty"`
+    Targets      []string             `json:"targets,omitempty"`
+    Binding      string               `json:"binding,omitempty"`
+    Stages       map[string]time.Time `json:"stages"`
+    Writers      []Writer             `json:"writers,omitempty"`
+}
+
+// Writer is one managedFields entry of an object: which field manager last
+// wrote it, how, and when.
+type Writer struct {
+    Manager     string    `json:"manager"`
+    Operation   string    `json:"operation"`
+    Subresource string    `json:"subresource,omitempty"`
+    Time        time.Time `json:"time"`
+}
+
+// Dataset is everything collected in a run. Collection tasks add to it
+// concurrently and it is handed to the analysis in memory.
+type Dataset struct {
+    mu sync.Mutex
+
+    Objects []ObjectMetrics `json:"objects"`
+    Works   []WorkMetrics   `json:"works"`
+}
+
+func (d *Dataset) AddObjects(metrics []ObjectMetrics) {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    d.Objects = append(d.Objects, metrics...)
+}
+
+func (d *Dataset) AddWorks(metrics []WorkMetrics) {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    d.Works = append(d.Works, metrics...)
 }
 
 type CollectionArgs struct {
@@ -28,4 +97,31 @@ type CollectionArgs struct {
     ExpType     string
     NumPods     int
     WatchSec    int
+    Workers     int
+    QPS         float32
+    Burst       int
+    Timeout     time.Duration
+    Retries     int
+
+    SkipPreflight bool
+    Formats       []string
+    SQLitePath    string
+    Parquet       bool
+
+    OpenMetricsPath string
+    Pushgateway     string
+    PushJob         string
+
+    OTLPFile     string
+    OTLPEndpoint string
+    ChromeTrace  string
+    HTMLReport   string
+    Markdown     string
+    JUnit        string
+
+    // ClockProbes is how many probe objects to write to each cluster to
+    // estimate its clock offset; zero skips the probe.
+    ClockProbes    int
+    ClockNamespace string
+    CorrectSkew    bool
 }
\ No newline at end of file
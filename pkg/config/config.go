@@ -0,0 +1,151 @@
+package config
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "time"
+
+    "sigs.k8s.io/yaml"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+)
+
+// Experiment is the experiment config file, in YAML or JSON.
+type Experiment struct {
+    // Stages define the latencies measured and reported, each as an end
+    // event minus a start event. Empty means analysis.DefaultStages.
+    Stages []analysis.Stage `json:"stages,omitempty"`
+
+    // Objectives are evaluated after analysis; any violation fails the run.
+    Objectives []analysis.Objective `json:"objectives,omitempty"`
+
+    // Repeat, when set, runs the load → converge → collect → cleanup cycle
+    // several times instead of collecting once.
+    Repeat *Repeat `json:"repeat,omitempty"`
+
+    // Sweep, when set, runs the repeated experiment once per combination of
+    // its parameters. It needs a repeat section with a load command.
+    Sweep *Sweep `json:"sweep,omitempty"`
+
+    // Outliers is the rule for flagging straggling objects per stage.
+    // Empty means analysis.DefaultOutlierRule.
+    Outliers *analysis.OutlierRule `json:"outliers,omitempty"`
+}
+
+// Repeat configures repeated runs. Load and Cleanup are shell commands run
+// with the experiment parameters in the environment.
+type Repeat struct {
+    Iterations int    `json:"iterations"`
+    Warmup     int    `json:"warmup,omitempty"`
+    Load       string `json:"load,omitempty"`
+    Cleanup    string `json:"cleanup,omitempty"`
+    // ConvergeTimeout bounds the wait for every WDS deployment to be
+    // available on the WEC after loading.
+    ConvergeTimeout Duration `json:"convergeTimeout,omitempty"`
+    // Pause is waited after cleanup, before the next iteration.
+    Pause Duration `json:"pause,omitempty"`
+}
+
+// Sweep is a matrix of experiment parameters. Namespaces replaces the
+// num-ns argument; the other parameters are only handed to the load command,
+// and an empty list leaves a parameter out of the matrix.
+type Sweep struct {
+    Namespaces          []int `json:"namespaces,omitempty"`
+    ObjectsPerNamespace []int `json:"objectsPerNamespace,omitempty"`
+    WECs                []int `json:"wecs,omitempty"`
+}
+
+// Duration is a time.Duration written as a string such as "90s" or "5m".
+type Duration struct {
+    time.Duration
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+    var s string
+    if err := json.Unmarshal(data, &s); err != nil {
+        return fmt.Errorf("invalid duration %s, want e.g. \"90s\"", data)
+    }
+    v, err := time.ParseDuration(s)
+    if err != nil {
+        return err
+    }
+    d.Duration = v
+    return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+    return json.Marshal(d.String())
+}
+
+// Load reads the experiment config at path.
+func Load(path string) (*Experiment, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read config: %v", err)
+    }
+    var exp Experiment
+    if err := yaml.UnmarshalStrict(data, &exp); err != nil {
+        return nil, fmt.Errorf("invalid config %s: %v", path, err)
+    }
+    if err := exp.validate(); err != nil {
+        return nil, fmt.Errorf("invalid config %s: %v", path, err)
+    }
+    return &exp, nil
+}
+
+// StageSet returns the stages of the experiment, or the defaults.
+func (e *Experiment) StageSet() []analysis.Stage {
+    if len(e.Stages) == 0 {
+        return analysis.DefaultStages
+    }
+    return e.Stages
+}
+
+// OutlierRule returns the outlier rule of the experiment, or the default.
+func (e *Experiment) OutlierRule() analysis.OutlierRule {
+    if e.Outliers == nil {
+        return analysis.DefaultOutlierRule
+    }
+    return *e.Outliers
+}
+
+func (e *Experiment) validate() error {
+    if len(e.Stages) > 0 {
+        if err := analysis.ValidateStages(e.Stages); err != nil {
+            return err
+        }
+    }
+    if err := analysis.CheckObjectives(e.Objectives, e.StageSet()); err != nil {
+        return err
+    }
+    if e.Outliers != nil {
+        if err := e.Outliers.Validate(); err != nil {
+            return fmt.Errorf("outliers: %v", err)
+        }
+    }
+    if r := e.Repeat; r != nil {
+        if r.Iterations < 1 {
+            return fmt.Errorf("repeat.iterations must be at least 1")
+        }
+        if r.Warmup < 0 {
+            return fmt.Errorf("repeat.warmup must not be negative")
+        }
+        if r.ConvergeTimeout.Duration == 0 {
+            r.ConvergeTimeout.Duration = 10 * time.Minute
+        }
+    }
+    if s := e.Sweep; s != nil {
+        if e.Repeat == nil || e.Repeat.Load == "" {
+            return fmt.Errorf("sweep needs repeat.load to create each cell's objects")
+        }
+        for name, values := range map[string][]int{"namespaces": s.Namespaces, "objectsPerNamespace": s.ObjectsPerNamespace, "wecs": s.WECs} {
+            for _, v := range values {
+                if v < 1 {
+                    return fmt.Errorf("sweep.%s values must be at least 1", name)
+                }
+            }
+        }
+    }
+    return nil
+}
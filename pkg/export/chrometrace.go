@@ -0,0 +1,219 @@
+package export
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "sort"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+    "github.com/asmit27rai/collector/pkg/collector"
+)
+
+// traceEvent is one entry of the Chrome Trace Event Format, which Perfetto
+// and chrome://tracing load directly. Times are in microseconds.
+type traceEvent struct {
+    Name  string                 `json:"name"`
+    Cat   string                 `json:"cat,omitempty"`
+    Phase string                 `json:"ph"`
+    TS    float64                `json:"ts"`
+    Dur   *float64               `json:"dur,omitempty"`
+    PID   int                    `json:"pid"`
+    TID   int                    `json:"tid"`
+    Scope string                 `json:"s,omitempty"`
+    Args  map[string]interface{} `json:"args,omitempty"`
+}
+
+// lanesPerKind spaces out thread IDs so each kind keeps its own range.
+const lanesPerKind = 1000
+
+// track is the per-cluster, per-kind group of slices for one object.
+type track struct {
+    cluster string
+    kind    string
+    start   time.Time
+    end     time.Time
+    slices  []traceEvent
+}
+
+// ChromeTrace lays the lifecycles of r out on a timeline: one process per
+// cluster, one track per kind and a slice per journey step of every object
+// of every kind, with times relative to the start of the earliest step.
+// Objects whose steps overlap on a cluster are spread over extra lanes of
+// the kind's track.
+func ChromeTrace(r *analysis.Result) []byte {
+    clusterOf := map[collector.Role]string{
+        collector.RoleWDS: r.Run.WDSContext,
+        collector.RoleITS: r.Run.ITSContext,
+    }
+
+    var tracks []*track
+    var origin, binding time.Time
+    for _, l := range r.AllLifecycles {
+        if t, ok := l.Time(analysis.BindingCreated); ok {
+            binding = t
+        }
+
+        byCluster := map[string]*track{}
+        for _, step := range journey {
+            start, end, ok := step.interval(l)
+            if !ok {
+                continue
+            }
+            cluster := clusterOf[step.Role]
+            if step.Role == collector.RoleWEC {
+                cluster = l.Cluster
+            }
+
+            t := byCluster[cluster]
+            if t == nil {
+                t = &track{cluster: cluster, kind: l.Kind, start: start, end: end}
+                byCluster[cluster] = t
+                tracks = append(tracks, t)
+            }
+
+            args := map[string]interface{}{
+                "namespace": l.Namespace,
+                "name":      l.Name,
+            }
+            if end.Before(start) {
+                args["negative_duration_ms"] = float64(start.Sub(end)) / float64(time.Millisecond)
+                end = start
+            }
+            if start.Before(t.start) {
+                t.start = start
+            }
+            if end.After(t.end) {
+                t.end = end
+            }
+            if origin.IsZero() || start.Before(origin) {
+                origin = start
+            }
+
+            dur := float64(end.Sub(start)) / float64(time.Microsecond)
+            t.slices = append(t.slices, traceEvent{
+                Name:  step.Name,
+                Cat:   l.Kind,
+                Phase: "X",
+                TS:    float64(start.UnixNano()) / float64(time.Microsecond),
+                Dur:   &dur,
+                Args:  args,
+            })
+        }
+    }
+    if !binding.IsZero() && binding.Before(origin) {
+        origin = binding
+    }
+
+    // Lay out clusters in journey order, then by name.
+    rank := map[collector.Role]int{collector.RoleWDS: 0, collector.RoleITS: 1, collector.RoleWEC: 2}
+    roleOf := map[string]collector.Role{}
+    for role, cluster := range clusterOf {
+        roleOf[cluster] = role
+    }
+    clusterRole := func(cluster string) collector.Role {
+        if role, ok := roleOf[cluster]; ok {
+            return role
+        }
+        return collector.RoleWEC
+    }
+    clusterRank := func(cluster string) int {
+        return rank[clusterRole(cluster)]
+    }
+    sort.SliceStable(tracks, func(i, j int) bool {
+        a, b := tracks[i], tracks[j]
+        if clusterRank(a.cluster) != clusterRank(b.cluster) {
+            return clusterRank(a.cluster) < clusterRank(b.cluster)
+        }
+        if a.cluster != b.cluster {
+            return a.cluster < b.cluster
+        }
+        if a.kind != b.kind {
+            return a.kind < b.kind
+        }
+        return a.start.Before(b.start)
+    })
+
+    var events []traceEvent
+    pids := map[string]int{}
+    kinds := map[string]int{}
+    // lanes holds, per cluster and kind, when each lane becomes free.
+    lanes := map[string][]time.Time{}
+    offset := float64(origin.UnixNano()) / float64(time.Microsecond)
+
+    for _, t := range tracks {
+        pid, ok := pids[t.cluster]
+        if !ok {
+            pid = len(pids) + 1
+            pids[t.cluster] = pid
+            name := fmt.Sprintf("%s (%s)", t.cluster, clusterRole(t.cluster))
+            events = append(events,
+                metadata("process_name", pid, 0, map[string]interface{}{"name": name}),
+                metadata("process_sort_index", pid, 0, map[string]interface{}{"sort_index": pid}),
+            )
+        }
+        kind, ok := kinds[t.kind]
+        if !ok {
+            kind = len(kinds)
+            kinds[t.kind] = kind
+        }
+
+        key := t.cluster + "/" + t.kind
+        lane := 0
+        for lane < len(lanes[key]) && lanes[key][lane].After(t.start) {
+            lane++
+        }
+        if lane == len(lanes[key]) {
+            lanes[key] = append(lanes[key], time.Time{})
+            name := t.kind
+            if lane > 0 {
+                name = fmt.Sprintf("%s [%d]", t.kind, lane+1)
+            }
+            tid := kind*lanesPerKind + lane
+            events = append(events,
+                metadata("thread_name", pid, tid, map[string]interface{}{"name": name}),
+                metadata("thread_sort_index", pid, tid, map[string]interface{}{"sort_index": tid}),
+            )
+        }
+        lanes[key][lane] = t.end
+
+        for _, s := range t.slices {
+            s.PID = pid
+            s.TID = kind*lanesPerKind + lane
+            s.TS -= offset
+            events = append(events, s)
+        }
+    }
+
+    if !binding.IsZero() {
+        events = append(events, traceEvent{
+            Name:  "BindingPolicy created",
+            Phase: "i",
+            TS:    float64(binding.UnixNano())/float64(time.Microsecond) - offset,
+            PID:   pids[r.Run.WDSContext],
+            Scope: "g",
+        })
+    }
+
+    data, _ := json.Marshal(map[string]interface{}{
+        "traceEvents":     events,
+        "displayTimeUnit": "ms",
+        "otherData": map[string]interface{}{
+            "run":      r.Run.ID,
+            "origin":   origin.UTC().Format(time.RFC3339Nano),
+            "complete": r.Run.Complete,
+        },
+    })
+    return data
+}
+
+func metadata(name string, pid, tid int, args map[string]interface{}) traceEvent {
+    return traceEvent{Name: name, Phase: "M", PID: pid, TID: tid, Args: args}
+}
+
+// WriteChromeTrace writes the timeline of r as Chrome Trace Event Format
+// JSON, ready to open in https://ui.perfetto.dev or chrome://tracing.
+func WriteChromeTrace(path string, r *analysis.Result) error {
+    return os.WriteFile(path, ChromeTrace(r), 0644)
+}
@@ -0,0 +1,142 @@
+package export
+
+import (
+    "bytes"
+    "context"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+)
+
+// LatencyBuckets are the histogram bucket upper bounds, in seconds.
+var LatencyBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600}
+
+const (
+    histogramName = "kubestellar_stage_latency_seconds"
+    summaryName   = "kubestellar_stage_latency_quantile_seconds"
+)
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// OpenMetrics renders the per-stage latency distributions of r as a
+// histogram and a summary, labelled by run, stage, kind and cluster. The
+// output is valid OpenMetrics text and is also accepted by Prometheus text
+// parsers such as node_exporter's textfile collector.
+func OpenMetrics(r *analysis.Result, stages []analysis.Stage) []byte {
+    return exposition(r, stages, true)
+}
+
+// PrometheusText renders the same metrics as OpenMetrics in the Prometheus
+// text format 0.0.4, which has no # UNIT or # EOF lines.
+func PrometheusText(r *analysis.Result, stages []analysis.Stage) []byte {
+    return exposition(r, stages, false)
+}
+
+func exposition(r *analysis.Result, stages []analysis.Stage, openMetrics bool) []byte {
+    series := analysis.GroupLatencies(r.Latencies, stages)
+    var buf bytes.Buffer
+
+    fmt.Fprintf(&buf, "# HELP %s Time between the start and end events of a KubeStellar stage, per object.\n", histogramName)
+    fmt.Fprintf(&buf, "# TYPE %s histogram\n", histogramName)
+    if openMetrics {
+        fmt.Fprintf(&buf, "# UNIT %s seconds\n", histogramName)
+    }
+    for _, s := range series {
+        labels := seriesLabels(r.Run.ID, s)
+        counts := make([]int, len(LatencyBuckets))
+        var sum float64
+        for _, v := range s.Values {
+            secs := v.Seconds()
+            sum += secs
+            for i, le := range LatencyBuckets {
+                if secs <= le {
+                    counts[i]++
+                }
+            }
+        }
+        for i, le := range LatencyBuckets {
+            fmt.Fprintf(&buf, "%s_bucket{%s,le=\"%s\"} %d\n", histogramName, labels, formatFloat(le), counts[i])
+        }
+        fmt.Fprintf(&buf, "%s_bucket{%s,le=\"+Inf\"} %d\n", histogramName, labels, len(s.Values))
+        fmt.Fprintf(&buf, "%s_sum{%s} %s\n", histogramName, labels, formatFloat(sum))
+        fmt.Fprintf(&buf, "%s_count{%s} %d\n", histogramName, labels, len(s.Values))
+    }
+
+    fmt.Fprintf(&buf, "# HELP %s Quantiles of the time between the start and end events of a KubeStellar stage.\n", summaryName)
+    fmt.Fprintf(&buf, "# TYPE %s summary\n", summaryName)
+    if openMetrics {
+        fmt.Fprintf(&buf, "# UNIT %s seconds\n", summaryName)
+    }
+    for _, s := range series {
+        labels := seriesLabels(r.Run.ID, s)
+        sum := analysis.Summarize(s.Values)
+        for _, q := range []struct {
+            q string
+            v time.Duration
+        }{{"0.5", sum.P50}, {"0.9", sum.P90}, {"0.95", sum.P95}, {"0.99", sum.P99}} {
+            fmt.Fprintf(&buf, "%s{%s,quantile=\"%s\"} %s\n", summaryName, labels, q.q, formatFloat(q.v.Seconds()))
+        }
+        fmt.Fprintf(&buf, "%s_sum{%s} %s\n", summaryName, labels, formatFloat(sum.Sum.Seconds()))
+        fmt.Fprintf(&buf, "%s_count{%s} %d\n", summaryName, labels, sum.Count)
+    }
+
+    if openMetrics {
+        buf.WriteString("# EOF\n")
+    }
+    return buf.Bytes()
+}
+
+// WriteOpenMetrics writes the exposition to path atomically, so a textfile
+// collector never reads a half-written file.
+func WriteOpenMetrics(path string, r *analysis.Result, stages []analysis.Stage) error {
+    if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+        return err
+    }
+    tmp := path + ".tmp"
+    if err := os.WriteFile(tmp, OpenMetrics(r, stages), 0644); err != nil {
+        return err
+    }
+    return os.Rename(tmp, path)
+}
+
+// Push sends the metrics in the Prometheus text format to a
+// Pushgateway-compatible endpoint, grouped under job and the run's ID.
+// Pushing again for the same run replaces it. Cancelling ctx aborts the push.
+func Push(ctx context.Context, client *http.Client, gateway, job string, r *analysis.Result, stages []analysis.Stage) error {
+    target := fmt.Sprintf("%s/metrics/job/%s/run/%s",
+        strings.TrimRight(gateway, "/"), url.PathEscape(job), url.PathEscape(r.Run.ID))
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, bytes.NewReader(PrometheusText(r, stages)))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+    resp, err := client.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode/100 != 2 {
+        body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+        return fmt.Errorf("push to %s failed: %s: %s", target, resp.Status, strings.TrimSpace(string(body)))
+    }
+    return nil
+}
+
+func seriesLabels(run string, s analysis.Series) string {
+    return fmt.Sprintf(`run="%s",stage="%s",kind="%s",cluster="%s"`,
+        labelEscaper.Replace(run), labelEscaper.Replace(s.Stage), labelEscaper.Replace(s.Kind), labelEscaper.Replace(s.Cluster))
+}
+
+func formatFloat(f float64) string {
+    return fmt.Sprintf("%g", f)
+}
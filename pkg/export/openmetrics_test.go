@@ -0,0 +1,121 @@
+package export
+
+import (
+    "context"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+)
+
+func testResult() *analysis.Result {
+    r := &analysis.Result{Run: analysis.Run{ID: "run 1/a"}}
+    for _, v := range []time.Duration{200 * time.Millisecond, 800 * time.Millisecond, 3 * time.Second} {
+        r.Latencies = append(r.Latencies, analysis.Latency{Stage: "Deployment→WEC", Kind: "deployments", Cluster: "cluster1", Value: v})
+    }
+    return r
+}
+
+// parseText checks every line of a Prometheus text exposition and returns
+// the samples by series, labels included.
+func parseText(t *testing.T, body string) map[string]float64 {
+    t.Helper()
+    samples := map[string]float64{}
+    for i, line := range strings.Split(strings.TrimSuffix(body, "\n"), "\n") {
+        if strings.HasPrefix(line, "#") {
+            fields := strings.Fields(line)
+            if len(fields) < 3 || (fields[1] != "HELP" && fields[1] != "TYPE") {
+                t.Fatalf("line %d: unexpected comment %q", i+1, line)
+            }
+            continue
+        }
+        sep := strings.LastIndex(line, " ")
+        if sep < 0 {
+            t.Fatalf("line %d: no value in %q", i+1, line)
+        }
+        series, value := line[:sep], line[sep+1:]
+        if open, end := strings.Index(series, "{"), strings.LastIndex(series, "}"); open >= 0 && end != len(series)-1 {
+            t.Fatalf("line %d: unterminated labels in %q", i+1, line)
+        }
+        v, err := strconv.ParseFloat(value, 64)
+        if err != nil {
+            t.Fatalf("line %d: %v", i+1, err)
+        }
+        samples[series] = v
+    }
+    return samples
+}
+
+func TestPush(t *testing.T) {
+    var method, path, contentType, body string
+    gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+        data, _ := io.ReadAll(req.Body)
+        method, path, contentType, body = req.Method, req.URL.EscapedPath(), req.Header.Get("Content-Type"), string(data)
+    }))
+    defer gateway.Close()
+
+    if err := Push(context.Background(), gateway.Client(), gateway.URL+"/", "perf job", testResult(), nil); err != nil {
+        t.Fatal(err)
+    }
+    if method != http.MethodPut {
+        t.Errorf("method = %s, want PUT", method)
+    }
+    if want := "/metrics/job/perf%20job/run/run%201%2Fa"; path != want {
+        t.Errorf("path = %s, want %s", path, want)
+    }
+    if !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+        t.Errorf("Content-Type = %q, want the Prometheus text format", contentType)
+    }
+
+    samples := parseText(t, body)
+    labels := `run="run 1/a",stage="Deployment→WEC",kind="deployments",cluster="cluster1"`
+    for series, want := range map[string]float64{
+        histogramName + `_bucket{` + labels + `,le="0.25"}`: 1,
+        histogramName + `_bucket{` + labels + `,le="1"}`:    2,
+        histogramName + `_bucket{` + labels + `,le="+Inf"}`: 3,
+        histogramName + `_sum{` + labels + `}`:              4,
+        histogramName + `_count{` + labels + `}`:            3,
+        summaryName + `_count{` + labels + `}`:              3,
+    } {
+        if got, ok := samples[series]; !ok || got != want {
+            t.Errorf("%s = %v (present %v), want %v", series, got, ok, want)
+        }
+    }
+}
+
+func TestPushError(t *testing.T) {
+    gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+        http.Error(w, "bad metrics", http.StatusBadRequest)
+    }))
+    defer gateway.Close()
+
+    err := Push(context.Background(), gateway.Client(), gateway.URL, "job", testResult(), nil)
+    if err == nil || !strings.Contains(err.Error(), "400") || !strings.Contains(err.Error(), "bad metrics") {
+        t.Fatalf("Push = %v, want an error with the status and body", err)
+    }
+}
+
+func TestPushCancelled(t *testing.T) {
+    gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+        <-req.Context().Done()
+    }))
+    defer gateway.Close()
+
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+    if err := Push(ctx, gateway.Client(), gateway.URL, "job", testResult(), nil); err == nil {
+        t.Fatal("Push succeeded with a cancelled context")
+    }
+}
+
+func TestOpenMetricsEOF(t *testing.T) {
+    out := string(OpenMetrics(testResult(), nil))
+    if !strings.HasSuffix(out, "# EOF\n") || !strings.Contains(out, "# UNIT "+histogramName+" seconds\n") {
+        t.Errorf("OpenMetrics output lacks # UNIT or # EOF:\n%s", out)
+    }
+}
@@ -0,0 +1,240 @@
+package export
+
+import (
+    "bytes"
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+    "github.com/asmit27rai/collector/pkg/collector"
+)
+
+// journeyStep is one hop of an object's way through KubeStellar, exported
+// as a span from the From event to the To event. Role is the cluster the hop
+// is attributed to, as in its name. Steps whose events were not observed are
+// left out.
+type journeyStep struct {
+    Name     string
+    Role     collector.Role
+    From, To []analysis.Event
+}
+
+// journey lists the hops in order, with the object's events of its own
+// kind. Where an event has a fallback the first observed one is used, e.g.
+// WEC status when the Available condition is missing. Kinds without a
+// status, such as ConfigMaps, end after "wec: apply manifests".
+var journey = []journeyStep{
+    {"wds: package into ManifestWork", collector.RoleWDS, []analysis.Event{analysis.OwnWDSCreated}, []analysis.Event{analysis.ManifestWorkCreated}},
+    {"its: deliver ManifestWork", collector.RoleITS, []analysis.Event{analysis.ManifestWorkCreated}, []analysis.Event{analysis.AppliedWorkCreated}},
+    {"wec: apply manifests", collector.RoleWEC, []analysis.Event{analysis.AppliedWorkCreated}, []analysis.Event{analysis.OwnWECCreated}},
+    {"wec: become ready", collector.RoleWEC, []analysis.Event{analysis.OwnWECCreated}, []analysis.Event{analysis.OwnWECAvailable, analysis.OwnWECStatus}},
+    {"its: report status", collector.RoleITS, []analysis.Event{analysis.OwnWECAvailable, analysis.OwnWECStatus}, []analysis.Event{analysis.WorkStatusCreated}},
+    {"wds: upsync status", collector.RoleWDS, []analysis.Event{analysis.WorkStatusCreated}, []analysis.Event{analysis.OwnWDSStatus}},
+}
+
+// interval returns the start and end of step in l, if both are known.
+func (step journeyStep) interval(l analysis.Lifecycle) (time.Time, time.Time, bool) {
+    start, ok := firstKnown(l, step.From)
+    if !ok {
+        return time.Time{}, time.Time{}, false
+    }
+    end, ok := firstKnown(l, step.To)
+    if !ok {
+        return time.Time{}, time.Time{}, false
+    }
+    return start, end, true
+}
+
+// OTLP/JSON payload types, see opentelemetry-proto's trace/v1 messages.
+type (
+    otlpTraces struct {
+        ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+    }
+    otlpResourceSpans struct {
+        Resource   otlpResource     `json:"resource"`
+        ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+    }
+    otlpResource struct {
+        Attributes []otlpKeyValue `json:"attributes"`
+    }
+    otlpScopeSpans struct {
+        Scope otlpScope  `json:"scope"`
+        Spans []otlpSpan `json:"spans"`
+    }
+    otlpScope struct {
+        Name string `json:"name"`
+    }
+    otlpSpan struct {
+        TraceID           string         `json:"traceId"`
+        SpanID            string         `json:"spanId"`
+        ParentSpanID      string         `json:"parentSpanId,omitempty"`
+        Name              string         `json:"name"`
+        Kind              int            `json:"kind"`
+        StartTimeUnixNano string         `json:"startTimeUnixNano"`
+        EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+        Attributes        []otlpKeyValue `json:"attributes"`
+    }
+    otlpKeyValue struct {
+        Key   string    `json:"key"`
+        Value otlpValue `json:"value"`
+    }
+    otlpValue struct {
+        StringValue *string `json:"stringValue,omitempty"`
+        IntValue    *string `json:"intValue,omitempty"`
+    }
+)
+
+// spanKindInternal is SPAN_KIND_INTERNAL.
+const spanKindInternal = 1
+
+// Traces converts the lifecycle of every object of r, of any kind, into a
+// trace: a root span covering the whole journey with one child span per
+// observed step. Trace and span IDs are derived from the run and object, so
+// re-exporting a run yields the same IDs.
+func Traces(r *analysis.Result) []byte {
+    var spans []otlpSpan
+    for _, l := range r.AllLifecycles {
+        spans = append(spans, lifecycleSpans(r.Run.ID, l)...)
+    }
+
+    payload := otlpTraces{ResourceSpans: []otlpResourceSpans{{
+        Resource: otlpResource{Attributes: []otlpKeyValue{
+            stringAttr("service.name", "kubestellar"),
+            stringAttr("kubestellar.run.id", r.Run.ID),
+            stringAttr("kubestellar.wds.context", r.Run.WDSContext),
+            stringAttr("kubestellar.its.context", r.Run.ITSContext),
+        }},
+        ScopeSpans: []otlpScopeSpans{{
+            Scope: otlpScope{Name: "github.com/asmit27rai/collector"},
+            Spans: spans,
+        }},
+    }}}
+
+    data, _ := json.Marshal(payload)
+    return data
+}
+
+func lifecycleSpans(runID string, l analysis.Lifecycle) []otlpSpan {
+    key := fmt.Sprintf("%s/%s/%s/%s", runID, l.Kind, l.Namespace, l.Name)
+    traceID := hashID(key, 16)
+    rootID := hashID(key+"/root", 8)
+
+    attrs := []otlpKeyValue{
+        stringAttr("k8s.cluster.name", l.Cluster),
+        stringAttr("k8s.namespace.name", l.Namespace),
+        stringAttr("kubestellar.object.kind", l.Kind),
+        stringAttr("kubestellar.object.name", l.Name),
+    }
+    if l.ManifestWork != "" {
+        attrs = append(attrs, stringAttr("kubestellar.manifestwork", l.ManifestWork))
+    }
+
+    var children []otlpSpan
+    var first, last time.Time
+    for i, step := range journey {
+        start, end, ok := step.interval(l)
+        if !ok {
+            continue
+        }
+
+        spanAttrs := append([]otlpKeyValue(nil), attrs...)
+        if end.Before(start) {
+            // Clock skew between clusters can order events backwards; keep
+            // the span but flag it instead of emitting a negative duration.
+            spanAttrs = append(spanAttrs, intAttr("kubestellar.negative_duration_ns", int64(start.Sub(end))))
+            end = start
+        }
+        children = append(children, span(traceID, hashID(fmt.Sprintf("%s/%d", key, i), 8), rootID, step.Name, start, end, spanAttrs))
+
+        if first.IsZero() || start.Before(first) {
+            first = start
+        }
+        if end.After(last) {
+            last = end
+        }
+    }
+    if len(children) == 0 {
+        return nil
+    }
+
+    root := span(traceID, rootID, "", fmt.Sprintf("%s %s/%s", strings.TrimSuffix(l.Kind, "s"), l.Namespace, l.Name), first, last, attrs)
+    return append([]otlpSpan{root}, children...)
+}
+
+func firstKnown(l analysis.Lifecycle, events []analysis.Event) (time.Time, bool) {
+    for _, e := range events {
+        if t, ok := l.Time(e); ok {
+            return t, true
+        }
+    }
+    return time.Time{}, false
+}
+
+func span(traceID, spanID, parentID, name string, start, end time.Time, attrs []otlpKeyValue) otlpSpan {
+    return otlpSpan{
+        TraceID:           traceID,
+        SpanID:            spanID,
+        ParentSpanID:      parentID,
+        Name:              name,
+        Kind:              spanKindInternal,
+        StartTimeUnixNano: strconv.FormatInt(start.UnixNano(), 10),
+        EndTimeUnixNano:   strconv.FormatInt(end.UnixNano(), 10),
+        Attributes:        attrs,
+    }
+}
+
+func hashID(key string, size int) string {
+    sum := sha256.Sum256([]byte(key))
+    return hex.EncodeToString(sum[:size])
+}
+
+func stringAttr(key, value string) otlpKeyValue {
+    return otlpKeyValue{Key: key, Value: otlpValue{StringValue: &value}}
+}
+
+func intAttr(key string, value int64) otlpKeyValue {
+    v := strconv.FormatInt(value, 10)
+    return otlpKeyValue{Key: key, Value: otlpValue{IntValue: &v}}
+}
+
+// WriteTraces writes the traces of r as an OTLP/JSON file.
+func WriteTraces(path string, r *analysis.Result) error {
+    return os.WriteFile(path, Traces(r), 0644)
+}
+
+// SendTraces posts the traces of r to an OTLP/HTTP endpoint such as
+// http://localhost:4318; /v1/traces is appended unless already present.
+// Cancelling ctx aborts the request.
+func SendTraces(ctx context.Context, client *http.Client, endpoint string, r *analysis.Result) error {
+    target := strings.TrimRight(endpoint, "/")
+    if !strings.HasSuffix(target, "/v1/traces") {
+        target += "/v1/traces"
+    }
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(Traces(r)))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+
+    resp, err := client.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode/100 != 2 {
+        body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+        return fmt.Errorf("sending traces to %s failed: %s: %s", target, resp.Status, strings.TrimSpace(string(body)))
+    }
+    return nil
+}
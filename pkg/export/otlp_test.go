@@ -0,0 +1,101 @@
+package export
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+    "github.com/asmit27rai/collector/pkg/collector"
+)
+
+// kindsResult has a Deployment and a ConfigMap delivered by one ManifestWork.
+func kindsResult() *analysis.Result {
+    start := time.Date(2025, 5, 26, 15, 0, 0, 0, time.UTC)
+    lifecycle := func(kind string) analysis.Lifecycle {
+        return analysis.Lifecycle{Kind: kind, Namespace: "perf-test-0", Name: "a", Cluster: "cluster1", Events: map[analysis.Event]time.Time{
+            {Role: collector.RoleWDS, Kind: kind, Source: collector.StageCreated}: start,
+            analysis.ManifestWorkCreated:                                          start.Add(time.Second),
+            analysis.AppliedWorkCreated:                                           start.Add(2 * time.Second),
+            {Role: collector.RoleWEC, Kind: kind, Source: collector.StageCreated}: start.Add(3 * time.Second),
+        }}
+    }
+    deployment, configMap := lifecycle("deployments"), lifecycle("configmaps")
+    return &analysis.Result{
+        Run:           analysis.Run{ID: "run", WDSContext: "wds1", ITSContext: "its1"},
+        Lifecycles:    []analysis.Lifecycle{deployment},
+        AllLifecycles: []analysis.Lifecycle{configMap, deployment},
+    }
+}
+
+func TestTracesCoverEveryKind(t *testing.T) {
+    var payload otlpTraces
+    if err := json.Unmarshal(Traces(kindsResult()), &payload); err != nil {
+        t.Fatal(err)
+    }
+    roots := map[string]int{}
+    for _, s := range payload.ResourceSpans[0].ScopeSpans[0].Spans {
+        if s.ParentSpanID == "" {
+            roots[s.Name]++
+        } else {
+            roots[""]++
+        }
+    }
+    // Three steps each: package, deliver and apply.
+    want := map[string]int{"deployment perf-test-0/a": 1, "configmap perf-test-0/a": 1, "": 6}
+    for name, n := range want {
+        if roots[name] != n {
+            t.Errorf("spans = %v, want %v", roots, want)
+            break
+        }
+    }
+}
+
+func TestChromeTraceTrackPerKind(t *testing.T) {
+    var trace struct {
+        TraceEvents []traceEvent `json:"traceEvents"`
+    }
+    if err := json.Unmarshal(ChromeTrace(kindsResult()), &trace); err != nil {
+        t.Fatal(err)
+    }
+    threads := map[string]bool{}
+    for _, e := range trace.TraceEvents {
+        if e.Name == "thread_name" {
+            threads[e.Args["name"].(string)] = true
+        }
+    }
+    if !threads["deployments"] || !threads["configmaps"] {
+        t.Errorf("tracks = %v, want deployments and configmaps", threads)
+    }
+}
+
+func TestSendTraces(t *testing.T) {
+    var path, contentType string
+    endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+        path, contentType = req.URL.Path, req.Header.Get("Content-Type")
+    }))
+    defer endpoint.Close()
+
+    if err := SendTraces(context.Background(), endpoint.Client(), endpoint.URL+"/", kindsResult()); err != nil {
+        t.Fatal(err)
+    }
+    if path != "/v1/traces" || contentType != "application/json" {
+        t.Errorf("POST %s as %q, want /v1/traces as application/json", path, contentType)
+    }
+}
+
+func TestSendTracesCancelled(t *testing.T) {
+    endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+        <-req.Context().Done()
+    }))
+    defer endpoint.Close()
+
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+    if err := SendTraces(ctx, endpoint.Client(), endpoint.URL, kindsResult()); err == nil {
+        t.Fatal("SendTraces succeeded with a cancelled context")
+    }
+}
@@ -0,0 +1,167 @@
+package rbac
+
+import (
+    "bytes"
+    "sort"
+    "strconv"
+    "strings"
+
+    "github.com/asmit27rai/collector/pkg/collector"
+    rbacv1 "k8s.io/api/rbac/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime"
+    "sigs.k8s.io/yaml"
+)
+
+// Manifests derives the least-privilege RBAC objects a cluster with the given
+// role needs for the collector to read reqs. Cluster-wide reads become a
+// ClusterRole bound with a ClusterRoleBinding; namespaced reads become a
+// ClusterRole that is only bound, with RoleBindings, in the namespaces that
+// are actually read. Requirements on different sets of namespaces get a
+// ClusterRole each, so no verb is granted beyond the namespaces it is needed in.
+func Manifests(reqs []collector.Requirement, role collector.Role, subject rbacv1.Subject, prefix string) []interface{} {
+    var clusterWide []collector.Requirement
+    var namespaceSets []string
+    namespaced := map[string][]collector.Requirement{}
+    for _, req := range reqs {
+        if req.Role != role {
+            continue
+        }
+        if len(req.Namespaces) == 0 {
+            clusterWide = append(clusterWide, req)
+            continue
+        }
+        set := strings.Join(sortedKeys(toSet(req.Namespaces)), ",")
+        if namespaced[set] == nil {
+            namespaceSets = append(namespaceSets, set)
+        }
+        namespaced[set] = append(namespaced[set], req)
+    }
+
+    labels := map[string]string{"app.kubernetes.io/name": "kubestellar-collector"}
+    var objs []interface{}
+
+    if len(clusterWide) > 0 {
+        name := prefix + "-" + string(role) + "-cluster"
+        objs = append(objs,
+            &rbacv1.ClusterRole{
+                TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole"},
+                ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+                Rules:      rules(clusterWide),
+            },
+            &rbacv1.ClusterRoleBinding{
+                TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRoleBinding"},
+                ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+                Subjects:   []rbacv1.Subject{subject},
+                RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: name},
+            },
+        )
+    }
+
+    for i, set := range namespaceSets {
+        name := prefix + "-" + string(role)
+        if i > 0 {
+            name += "-" + strconv.Itoa(i+1)
+        }
+        objs = append(objs, &rbacv1.ClusterRole{
+            TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole"},
+            ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+            Rules:      rules(namespaced[set]),
+        })
+        for _, ns := range strings.Split(set, ",") {
+            objs = append(objs, &rbacv1.RoleBinding{
+                TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "RoleBinding"},
+                ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns, Labels: labels},
+                Subjects:   []rbacv1.Subject{subject},
+                RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: name},
+            })
+        }
+    }
+    return objs
+}
+
+// rules folds requirements into one PolicyRule per API group, verb set and
+// set of object names.
+func rules(reqs []collector.Requirement) []rbacv1.PolicyRule {
+    type key struct{ group, verbs, names string }
+    resources := map[key]map[string]bool{}
+    verbsOf := map[key][]string{}
+
+    for _, req := range reqs {
+        verbs := append([]string(nil), req.Verbs...)
+        sort.Strings(verbs)
+        k := key{req.GVR.Group, strings.Join(verbs, ","), strings.Join(sortedKeys(toSet(req.Names)), ",")}
+        if resources[k] == nil {
+            resources[k] = map[string]bool{}
+            verbsOf[k] = verbs
+        }
+        resources[k][req.GVR.Resource] = true
+    }
+
+    keys := make([]key, 0, len(resources))
+    for k := range resources {
+        keys = append(keys, k)
+    }
+    sort.Slice(keys, func(i, j int) bool {
+        if keys[i].group != keys[j].group {
+            return keys[i].group < keys[j].group
+        }
+        if keys[i].verbs != keys[j].verbs {
+            return keys[i].verbs < keys[j].verbs
+        }
+        return keys[i].names < keys[j].names
+    })
+
+    var out []rbacv1.PolicyRule
+    for _, k := range keys {
+        rule := rbacv1.PolicyRule{
+            APIGroups: []string{k.group},
+            Resources: sortedKeys(resources[k]),
+            Verbs:     verbsOf[k],
+        }
+        if k.names != "" {
+            rule.ResourceNames = strings.Split(k.names, ",")
+        }
+        out = append(out, rule)
+    }
+    return out
+}
+
+// YAML renders objs as a multi-document YAML stream.
+func YAML(objs []interface{}) ([]byte, error) {
+    var buf bytes.Buffer
+    for _, obj := range objs {
+        u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+        if err != nil {
+            return nil, err
+        }
+        // Drop the "creationTimestamp: null" every typed object carries.
+        unstructured.RemoveNestedField(u, "metadata", "creationTimestamp")
+
+        data, err := yaml.Marshal(u)
+        if err != nil {
+            return nil, err
+        }
+        buf.WriteString("---\n")
+        buf.Write(data)
+    }
+    return buf.Bytes(), nil
+}
+
+func toSet(values []string) map[string]bool {
+    set := make(map[string]bool, len(values))
+    for _, v := range values {
+        set[v] = true
+    }
+    return set
+}
+
+func sortedKeys(m map[string]bool) []string {
+    keys := make([]string, 0, len(m))
+    for k := range m {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    return keys
+}
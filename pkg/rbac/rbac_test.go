@@ -0,0 +1,113 @@
+package rbac
+
+import (
+    "fmt"
+    "reflect"
+    "strings"
+    "testing"
+
+    "github.com/asmit27rai/collector/pkg/collector"
+    rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// describe renders a generated object as one line per rule or binding, so
+// whole manifests compare at a glance.
+func describe(t *testing.T, obj interface{}) []string {
+    t.Helper()
+    rule := func(r rbacv1.PolicyRule) string {
+        s := fmt.Sprintf("  %s %s [%s]", strings.Join(r.APIGroups, ","), strings.Join(r.Resources, ","), strings.Join(r.Verbs, ","))
+        if len(r.ResourceNames) > 0 {
+            s += " names=" + strings.Join(r.ResourceNames, ",")
+        }
+        return s
+    }
+    switch o := obj.(type) {
+    case *rbacv1.ClusterRole:
+        out := []string{"ClusterRole " + o.Name}
+        for _, r := range o.Rules {
+            out = append(out, rule(r))
+        }
+        return out
+    case *rbacv1.ClusterRoleBinding:
+        return []string{fmt.Sprintf("ClusterRoleBinding %s -> %s %s for %s", o.Name, o.RoleRef.Kind, o.RoleRef.Name, o.Subjects[0].Name)}
+    case *rbacv1.RoleBinding:
+        return []string{fmt.Sprintf("RoleBinding %s/%s -> %s %s for %s", o.Namespace, o.Name, o.RoleRef.Kind, o.RoleRef.Name, o.Subjects[0].Name)}
+    }
+    t.Fatalf("unexpected object %T", obj)
+    return nil
+}
+
+func TestManifests(t *testing.T) {
+    subject := rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "me"}
+    args := collector.CollectionArgs{NumNS: 2, WECContext: "cluster1"}
+    probing := args
+    probing.ClockProbes, probing.ClockNamespace = 3, "default"
+
+    for _, tc := range []struct {
+        name string
+        args collector.CollectionArgs
+        role collector.Role
+        want []string
+    }{
+        {"wds", args, collector.RoleWDS, []string{
+            "ClusterRole p-wds-cluster",
+            "  control.kubestellar.io bindingpolicies [get] names=nginx-bpolicy",
+            "ClusterRoleBinding p-wds-cluster -> ClusterRole p-wds-cluster for me",
+            "ClusterRole p-wds",
+            "   configmaps,secrets,services [list]",
+            "  apps deployments [list]",
+            "RoleBinding perf-test-0/p-wds -> ClusterRole p-wds for me",
+            "RoleBinding perf-test-1/p-wds -> ClusterRole p-wds for me",
+        }},
+        {"its", args, collector.RoleITS, []string{
+            "ClusterRole p-its",
+            "  control.kubestellar.io workstatuses [list]",
+            "  work.open-cluster-management.io manifestworks [list]",
+            "RoleBinding cluster1/p-its -> ClusterRole p-its for me",
+        }},
+        {"wec", args, collector.RoleWEC, []string{
+            "ClusterRole p-wec-cluster",
+            "  work.open-cluster-management.io appliedmanifestworks [list]",
+            "ClusterRoleBinding p-wec-cluster -> ClusterRole p-wec-cluster for me",
+            "ClusterRole p-wec",
+            "   configmaps,secrets,services [list]",
+            "  apps deployments [list]",
+            "RoleBinding perf-test-0/p-wec -> ClusterRole p-wec for me",
+            "RoleBinding perf-test-1/p-wec -> ClusterRole p-wec for me",
+        }},
+        // Clock probes write ConfigMaps in one namespace only.
+        {"its with clock probes", probing, collector.RoleITS, []string{
+            "ClusterRole p-its",
+            "  control.kubestellar.io workstatuses [list]",
+            "  work.open-cluster-management.io manifestworks [list]",
+            "RoleBinding cluster1/p-its -> ClusterRole p-its for me",
+            "ClusterRole p-its-2",
+            "   configmaps [create,delete]",
+            "RoleBinding default/p-its-2 -> ClusterRole p-its-2 for me",
+        }},
+    } {
+        t.Run(tc.name, func(t *testing.T) {
+            var got []string
+            for _, obj := range Manifests(collector.Requirements(tc.args), tc.role, subject, "p") {
+                got = append(got, describe(t, obj)...)
+            }
+            if !reflect.DeepEqual(got, tc.want) {
+                t.Errorf("Manifests =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tc.want, "\n"))
+            }
+        })
+    }
+}
+
+func TestYAMLResourceNames(t *testing.T) {
+    reqs := collector.Requirements(collector.CollectionArgs{NumNS: 1, WECContext: "cluster1"})
+    out, err := YAML(Manifests(reqs, collector.RoleWDS, rbacv1.Subject{Kind: rbacv1.UserKind, Name: "me"}, "p"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !strings.Contains(string(out), "resourceNames:\n  - nginx-bpolicy\n") {
+        t.Errorf("YAML lacks resourceNames for the binding policy:\n%s", out)
+    }
+    if strings.Contains(string(out), "creationTimestamp") {
+        t.Errorf("YAML carries creationTimestamp:\n%s", out)
+    }
+}
@@ -0,0 +1,190 @@
+package report
+
+import (
+    "fmt"
+    "html/template"
+    "math"
+    "sort"
+    "strings"
+    "time"
+)
+
+// Charts are drawn as inline SVG so the report needs no scripts or network.
+const (
+    chartWidth  = 640
+    chartHeight = 240
+    marginLeft  = 48
+    marginRight = 16
+    marginTop   = 12
+    marginBot   = 36
+)
+
+var palette = []string{"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f", "#edc948", "#b07aa1", "#ff9da7", "#9c755f", "#bab0ac"}
+
+// line is one named series of points in a line chart. Markers draws a dot
+// on every point, labelled with its y value in seconds.
+type line struct {
+    Name    string
+    Color   string
+    Points  [][2]float64
+    Markers bool
+}
+
+// axes maps data coordinates onto the plot area. X values are seconds
+// unless xFormat says otherwise.
+type axes struct {
+    xMax, yMax float64
+    xFormat    func(float64) string
+}
+
+func (a axes) x(v float64) float64 {
+    return marginLeft + v/a.xMax*(chartWidth-marginLeft-marginRight)
+}
+
+func (a axes) y(v float64) float64 {
+    return chartHeight - marginBot - v/a.yMax*(chartHeight-marginTop-marginBot)
+}
+
+func (a axes) xLabel(v float64) string {
+    if a.xFormat != nil {
+        return a.xFormat(v)
+    }
+    return formatSeconds(v)
+}
+
+// frame draws the axes with a few ticks and their labels.
+func (a axes) frame(b *strings.Builder, xLabel, yLabel string, yFormat func(float64) string) {
+    fmt.Fprintf(b, `<svg viewBox="0 0 %d %d" width="%d" height="%d" role="img">`, chartWidth, chartHeight, chartWidth, chartHeight)
+    for i := 0; i <= 4; i++ {
+        v := a.yMax * float64(i) / 4
+        fmt.Fprintf(b, `<line x1="%d" x2="%d" y1="%.1f" y2="%.1f" class="grid"/>`, marginLeft, chartWidth-marginRight, a.y(v), a.y(v))
+        fmt.Fprintf(b, `<text x="%d" y="%.1f" class="tick" text-anchor="end">%s</text>`, marginLeft-4, a.y(v)+4, yFormat(v))
+    }
+    for i := 0; i <= 5; i++ {
+        v := a.xMax * float64(i) / 5
+        fmt.Fprintf(b, `<text x="%.1f" y="%d" class="tick" text-anchor="middle">%s</text>`, a.x(v), chartHeight-marginBot+14, a.xLabel(v))
+    }
+    fmt.Fprintf(b, `<text x="%d" y="%d" class="label" text-anchor="middle">%s</text>`, (chartWidth+marginLeft)/2, chartHeight-4, template.HTMLEscapeString(xLabel))
+    fmt.Fprintf(b, `<text x="12" y="%d" class="label" text-anchor="middle" transform="rotate(-90 12 %d)">%s</text>`, chartHeight/2, chartHeight/2, template.HTMLEscapeString(yLabel))
+}
+
+// histogram draws values as bins of equal width from zero to the maximum.
+func histogram(values []time.Duration, color string) template.HTML {
+    const bins = 20
+    if len(values) == 0 {
+        return ""
+    }
+    max := 0.0
+    for _, v := range values {
+        max = math.Max(max, v.Seconds())
+    }
+    if max <= 0 {
+        max = 1
+    }
+
+    counts := make([]int, bins)
+    top := 0
+    for _, v := range values {
+        i := int(v.Seconds() / max * bins)
+        if i >= bins {
+            i = bins - 1
+        }
+        if i < 0 {
+            i = 0
+        }
+        counts[i]++
+        if counts[i] > top {
+            top = counts[i]
+        }
+    }
+
+    a := axes{xMax: max, yMax: float64(top)}
+    var b strings.Builder
+    a.frame(&b, "latency", "objects", func(v float64) string { return fmt.Sprintf("%.0f", v) })
+    width := max / bins
+    for i, n := range counts {
+        if n == 0 {
+            continue
+        }
+        x0, x1 := a.x(float64(i)*width), a.x(float64(i+1)*width)
+        fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s–%s: %d</title></rect>`,
+            x0+0.5, a.y(float64(n)), x1-x0-1, a.y(0)-a.y(float64(n)), color,
+            formatSeconds(float64(i)*width), formatSeconds(float64(i+1)*width), n)
+    }
+    b.WriteString(`</svg>`)
+    return template.HTML(b.String())
+}
+
+// cdf draws the cumulative distribution of each stage as a step line.
+func cdf(stages []string, values map[string][]time.Duration) template.HTML {
+    var lines []line
+    max := 0.0
+    for i, stage := range stages {
+        sorted := append([]time.Duration(nil), values[stage]...)
+        if len(sorted) == 0 {
+            continue
+        }
+        sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+        l := line{Name: stage, Color: palette[i%len(palette)], Points: [][2]float64{{math.Max(sorted[0].Seconds(), 0), 0}}}
+        for j, v := range sorted {
+            s := math.Max(v.Seconds(), 0)
+            l.Points = append(l.Points, [2]float64{s, float64(j) / float64(len(sorted))}, [2]float64{s, float64(j+1) / float64(len(sorted))})
+            max = math.Max(max, s)
+        }
+        lines = append(lines, l)
+    }
+    return lineChart(lines, axes{xMax: nonZero(max), yMax: 1}, "latency", "fraction of objects",
+        func(v float64) string { return fmt.Sprintf("%.0f%%", v*100) })
+}
+
+// lineChart draws lines on a with a legend.
+func lineChart(lines []line, a axes, xLabel, yLabel string, yFormat func(float64) string) template.HTML {
+    if len(lines) == 0 {
+        return ""
+    }
+    var b strings.Builder
+    a.frame(&b, xLabel, yLabel, yFormat)
+    for _, l := range lines {
+        points := make([]string, len(l.Points))
+        for i, p := range l.Points {
+            points[i] = fmt.Sprintf("%.1f,%.1f", a.x(p[0]), a.y(p[1]))
+        }
+        fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="1.5" points="%s"><title>%s</title></polyline>`,
+            l.Color, strings.Join(points, " "), template.HTMLEscapeString(l.Name))
+        if l.Markers {
+            for _, p := range l.Points {
+                fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"><title>%s: %s at %s</title></circle>`,
+                    a.x(p[0]), a.y(p[1]), l.Color, template.HTMLEscapeString(l.Name), formatSeconds(p[1]), a.xLabel(p[0]))
+            }
+        }
+    }
+    b.WriteString(`</svg><ul class="legend">`)
+    for _, l := range lines {
+        fmt.Fprintf(&b, `<li><span style="background:%s"></span>%s</li>`, l.Color, template.HTMLEscapeString(l.Name))
+    }
+    b.WriteString(`</ul>`)
+    return template.HTML(b.String())
+}
+
+func nonZero(v float64) float64 {
+    if v <= 0 {
+        return 1
+    }
+    return v
+}
+
+// formatSeconds renders a duration in seconds with a precision that suits
+// its size.
+func formatSeconds(s float64) string {
+    switch {
+    case s == 0:
+        return "0"
+    case math.Abs(s) < 1:
+        return fmt.Sprintf("%.0fms", s*1000)
+    case math.Abs(s) < 10:
+        return fmt.Sprintf("%.1fs", s)
+    default:
+        return fmt.Sprintf("%.0fs", s)
+    }
+}
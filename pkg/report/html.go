@@ -0,0 +1,219 @@
+package report
+
+import (
+    _ "embed"
+    "fmt"
+    "html/template"
+    "io"
+    "os"
+    "path/filepath"
+    "sort"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+)
+
+//go:embed report.html
+var htmlSource string
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+    "seconds": func(d time.Duration) string { return formatSeconds(d.Seconds()) },
+    "time":    func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+}).Parse(htmlSource))
+
+// stageReport is one stage's section of the report.
+type stageReport struct {
+    Name      string
+    Summary   analysis.Summary
+    Histogram template.HTML
+}
+
+// namespaceRow is one namespace in the per-namespace table, with a summary
+// per stage in the order of the report's stages.
+type namespaceRow struct {
+    Namespace string
+    Objects   int
+    Stages    []analysis.Summary
+}
+
+type htmlData struct {
+    Run        analysis.Run
+    Duration   time.Duration
+    Objects    int
+    Works      int
+    Lifecycles int
+    Stages     []stageReport
+    CDF        template.HTML
+    Throughput template.HTML
+    Rates      template.HTML
+    Backlog    template.HTML
+    Namespaces []namespaceRow
+    Quality    []analysis.StageQuality
+    Warnings   []string
+}
+
+// throughputEvents are the milestones counted in the objects-over-time chart.
+var throughputEvents = []struct {
+    Name   string
+    Events []analysis.Event
+}{
+    {"created on WDS", []analysis.Event{analysis.OwnWDSCreated}},
+    {"ManifestWork created", []analysis.Event{analysis.ManifestWorkCreated}},
+    {"created on WEC", []analysis.Event{analysis.OwnWECCreated}},
+    {"ready on WEC", []analysis.Event{analysis.OwnWECAvailable, analysis.OwnWECStatus}},
+    {"status on WDS", []analysis.Event{analysis.OwnWDSStatus}},
+}
+
+// HTML writes a self-contained report of r: run metadata, data-quality
+// warnings, a histogram per stage, CDFs of all stages, objects-over-time,
+// throughput and backlog charts and a per-namespace table. Everything is
+// inlined so the file works offline.
+func HTML(w io.Writer, r *analysis.Result, stages []analysis.Stage) error {
+    data := htmlData{
+        Run:        r.Run,
+        Lifecycles: len(r.Lifecycles),
+        Quality:    r.Quality,
+        Warnings:   r.Warnings,
+    }
+    if !r.Run.Finished.IsZero() {
+        data.Duration = r.Run.Finished.Sub(r.Run.Started).Round(time.Second)
+    }
+    if r.Dataset != nil {
+        data.Objects = len(r.Dataset.Objects)
+        data.Works = len(r.Dataset.Works)
+    }
+
+    values := map[string][]time.Duration{}
+    byNamespace := map[string]map[string][]time.Duration{}
+    for _, l := range r.Latencies {
+        values[l.Stage] = append(values[l.Stage], l.Value)
+        if byNamespace[l.Namespace] == nil {
+            byNamespace[l.Namespace] = map[string][]time.Duration{}
+        }
+        byNamespace[l.Namespace][l.Stage] = append(byNamespace[l.Namespace][l.Stage], l.Value)
+    }
+
+    var names []string
+    for i, s := range stages {
+        names = append(names, s.Name)
+        data.Stages = append(data.Stages, stageReport{
+            Name:      s.Name,
+            Summary:   analysis.Summarize(values[s.Name]),
+            Histogram: histogram(values[s.Name], palette[i%len(palette)]),
+        })
+    }
+    data.CDF = cdf(names, values)
+    data.Throughput = throughput(r.AllLifecycles)
+    tp := analysis.ComputeThroughput(r.AllLifecycles)
+    data.Rates = seriesChart(tp, analysis.UnitRate)
+    data.Backlog = seriesChart(tp, analysis.UnitBacklog)
+
+    objects := map[string]int{}
+    for _, l := range r.Lifecycles {
+        objects[l.Namespace]++
+    }
+    for ns, count := range objects {
+        row := namespaceRow{Namespace: ns, Objects: count}
+        for _, s := range stages {
+            row.Stages = append(row.Stages, analysis.Summarize(byNamespace[ns][s.Name]))
+        }
+        data.Namespaces = append(data.Namespaces, row)
+    }
+    sort.Slice(data.Namespaces, func(i, j int) bool {
+        a, b := data.Namespaces[i].Namespace, data.Namespaces[j].Namespace
+        // perf-test-2 before perf-test-10
+        if len(a) != len(b) {
+            return len(a) < len(b)
+        }
+        return a < b
+    })
+
+    return htmlTemplate.Execute(w, data)
+}
+
+// throughput counts, over time since the first event, how many objects have
+// reached each milestone.
+func throughput(lifecycles []analysis.Lifecycle) template.HTML {
+    var origin time.Time
+    times := make([][]time.Time, len(throughputEvents))
+    for _, l := range lifecycles {
+        for i, m := range throughputEvents {
+            for _, e := range m.Events {
+                if t, ok := l.Time(e); ok {
+                    times[i] = append(times[i], t)
+                    if origin.IsZero() || t.Before(origin) {
+                        origin = t
+                    }
+                    break
+                }
+            }
+        }
+    }
+
+    var lines []line
+    xMax, yMax := 0.0, 0.0
+    for i, m := range throughputEvents {
+        if len(times[i]) == 0 {
+            continue
+        }
+        sort.Slice(times[i], func(a, b int) bool { return times[i][a].Before(times[i][b]) })
+
+        l := line{Name: m.Name, Color: palette[i%len(palette)], Points: [][2]float64{{0, 0}}}
+        for n, t := range times[i] {
+            x := t.Sub(origin).Seconds()
+            l.Points = append(l.Points, [2]float64{x, float64(n)}, [2]float64{x, float64(n + 1)})
+            if x > xMax {
+                xMax = x
+            }
+        }
+        if n := float64(len(times[i])); n > yMax {
+            yMax = n
+        }
+        lines = append(lines, l)
+    }
+    // Carry every line on to the end of the chart.
+    for i := range lines {
+        last := lines[i].Points[len(lines[i].Points)-1]
+        lines[i].Points = append(lines[i].Points, [2]float64{xMax, last[1]})
+    }
+    return lineChart(lines, axes{xMax: nonZero(xMax), yMax: nonZero(yMax)}, "time since first event", "objects",
+        func(v float64) string { return fmt.Sprintf("%.0f", v) })
+}
+
+// seriesChart draws the series of tp in unit as step lines over time.
+func seriesChart(tp analysis.Throughput, unit string) template.HTML {
+    step := tp.Interval.Seconds()
+    var lines []line
+    xMax, yMax := 0.0, 0.0
+    for i, s := range tp.Series {
+        if s.Unit != unit {
+            continue
+        }
+        l := line{Name: s.Name, Color: palette[i%len(palette)]}
+        for j, v := range s.Values {
+            x := float64(j) * step
+            l.Points = append(l.Points, [2]float64{x, v}, [2]float64{x + step, v})
+            yMax = max(yMax, v)
+        }
+        xMax = max(xMax, float64(len(s.Values))*step)
+        lines = append(lines, l)
+    }
+    return lineChart(lines, axes{xMax: nonZero(xMax), yMax: nonZero(yMax)}, "time since "+tp.Start.UTC().Format(time.TimeOnly), unit,
+        func(v float64) string { return fmt.Sprintf("%.0f", v) })
+}
+
+// WriteHTML writes the report of r to path, creating its directory.
+func WriteHTML(path string, r *analysis.Result, stages []analysis.Stage) error {
+    if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+        return err
+    }
+    f, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    if err := HTML(f, r, stages); err != nil {
+        f.Close()
+        return err
+    }
+    return f.Close()
+}
@@ -0,0 +1,108 @@
+package report
+
+import (
+    "encoding/xml"
+    "io"
+    "os"
+    "path/filepath"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+)
+
+// JUnit XML as understood by Jenkins, GitLab and the GitHub test reporters.
+type (
+    junitSuites struct {
+        XMLName  xml.Name     `xml:"testsuites"`
+        Name     string       `xml:"name,attr"`
+        Tests    int          `xml:"tests,attr"`
+        Failures int          `xml:"failures,attr"`
+        Suites   []junitSuite `xml:"testsuite"`
+    }
+    junitSuite struct {
+        Name       string          `xml:"name,attr"`
+        Tests      int             `xml:"tests,attr"`
+        Failures   int             `xml:"failures,attr"`
+        Timestamp  string          `xml:"timestamp,attr,omitempty"`
+        Properties []junitProperty `xml:"properties>property,omitempty"`
+        Cases      []junitCase     `xml:"testcase"`
+    }
+    junitProperty struct {
+        Name  string `xml:"name,attr"`
+        Value string `xml:"value,attr"`
+    }
+    junitCase struct {
+        Name      string        `xml:"name,attr"`
+        ClassName string        `xml:"classname,attr"`
+        Failure   *junitFailure `xml:"failure,omitempty"`
+        SystemOut string        `xml:"system-out,omitempty"`
+    }
+    junitFailure struct {
+        Message string `xml:"message,attr"`
+        Type    string `xml:"type,attr"`
+        Text    string `xml:",chardata"`
+    }
+)
+
+// JUnit writes checks as a JUnit XML report with one test suite per check
+// suite and one test case per check. Failed checks carry the measured value
+// and the threshold in their failure message.
+func JUnit(w io.Writer, r *analysis.Result, checks []analysis.Check) error {
+    report := junitSuites{Name: "kubestellar-latency " + r.Run.ID}
+    index := map[string]int{}
+    for _, c := range checks {
+        i, ok := index[c.Suite]
+        if !ok {
+            i = len(report.Suites)
+            index[c.Suite] = i
+            report.Suites = append(report.Suites, junitSuite{
+                Name:      c.Suite,
+                Timestamp: r.Run.Started.UTC().Format(time.RFC3339),
+                Properties: []junitProperty{
+                    {"run", r.Run.ID},
+                    {"wds", r.Run.WDSContext},
+                    {"its", r.Run.ITSContext},
+                    {"wec", r.Run.WECContext},
+                },
+            })
+        }
+
+        tc := junitCase{Name: c.Name, ClassName: "kubestellar." + c.Suite, SystemOut: c.Message()}
+        if !c.Passed {
+            tc.Failure = &junitFailure{Message: c.Message(), Type: "threshold", Text: "measured: " + c.Measured + "\nthreshold: " + c.Threshold}
+            report.Suites[i].Failures++
+            report.Failures++
+        }
+        report.Suites[i].Cases = append(report.Suites[i].Cases, tc)
+        report.Suites[i].Tests++
+        report.Tests++
+    }
+
+    if _, err := io.WriteString(w, xml.Header); err != nil {
+        return err
+    }
+    enc := xml.NewEncoder(w)
+    enc.Indent("", "  ")
+    if err := enc.Encode(report); err != nil {
+        return err
+    }
+    _, err := io.WriteString(w, "\n")
+    return err
+}
+
+// WriteJUnit writes the JUnit XML report of checks to path, creating its
+// directory.
+func WriteJUnit(path string, r *analysis.Result, checks []analysis.Check) error {
+    if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+        return err
+    }
+    f, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    if err := JUnit(f, r, checks); err != nil {
+        f.Close()
+        return err
+    }
+    return f.Close()
+}
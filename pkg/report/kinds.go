@@ -0,0 +1,70 @@
+package report
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "strings"
+    "text/tabwriter"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+)
+
+// Kinds writes the downsync latency of every kind side by side, each
+// followed by its breakdown by object size.
+func Kinds(w io.Writer, kinds []analysis.KindSummary) error {
+    var b strings.Builder
+    title := "Downsync by kind (WDS created → WEC created)"
+    fmt.Fprintf(&b, "%s\n%s\n", title, strings.Repeat("-", len([]rune(title))))
+    tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(tw, "Kind\tObjects\tDelivered\tMean size\tP50\tP90\tP99\tMax")
+    for _, k := range kinds {
+        fmt.Fprintf(tw, "%s\t%d\t%s\n", k.Kind, k.Objects, summaryColumns(k.Downsync, -1))
+        for _, s := range k.Sizes {
+            fmt.Fprintf(tw, "  %s\t\t%s\n", s.Class, summaryColumns(s.Downsync, s.MeanSize))
+        }
+    }
+    tw.Flush()
+
+    _, err := io.WriteString(w, b.String())
+    return err
+}
+
+// summaryColumns renders the delivered count, mean size and percentiles of
+// s. A negative size is left blank.
+func summaryColumns(s analysis.Summary, size int) string {
+    meanSize := ""
+    if size >= 0 {
+        meanSize = formatBytes(size)
+    }
+    if s.Count == 0 {
+        return fmt.Sprintf("0\t%s\t-\t-\t-\t-", meanSize)
+    }
+    round := func(d time.Duration) time.Duration { return d.Round(time.Millisecond) }
+    return fmt.Sprintf("%d\t%s\t%v\t%v\t%v\t%v", s.Count, meanSize, round(s.P50), round(s.P90), round(s.P99), round(s.Max))
+}
+
+func formatBytes(n int) string {
+    switch {
+    case n < 1<<10:
+        return fmt.Sprintf("%dB", n)
+    case n < 1<<20:
+        return fmt.Sprintf("%.1fKiB", float64(n)/(1<<10))
+    default:
+        return fmt.Sprintf("%.1fMiB", float64(n)/(1<<20))
+    }
+}
+
+// WriteKinds writes the Kinds report to path.
+func WriteKinds(path string, kinds []analysis.KindSummary) error {
+    f, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    if err := Kinds(f, kinds); err != nil {
+        f.Close()
+        return err
+    }
+    return f.Close()
+}
@@ -0,0 +1,105 @@
+package report
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+)
+
+// Markdown writes a summary of r suitable for a pull request comment: the
+// verdict, a latency table per stage, every check and the data-quality
+// warnings.
+func Markdown(w io.Writer, r *analysis.Result, stages []analysis.Stage, checks []analysis.Check) error {
+    var b strings.Builder
+
+    failed := 0
+    for _, c := range checks {
+        if !c.Passed {
+            failed++
+        }
+    }
+    verdict := "✅ all checks passed"
+    if failed > 0 {
+        verdict = fmt.Sprintf("❌ %d of %d checks failed", failed, len(checks))
+    }
+    if !r.Run.Complete {
+        verdict += ", ⚠️ collection incomplete"
+    }
+
+    fmt.Fprintf(&b, "## KubeStellar latency: %s\n\n", verdict)
+    fmt.Fprintf(&b, "Run `%s` on WDS `%s`, ITS `%s`, WEC `%s` with %d namespaces, %d objects correlated.\n\n",
+        r.Run.ID, r.Run.WDSContext, r.Run.ITSContext, r.Run.WECContext, r.Run.NumNS, len(r.Lifecycles))
+
+    values := map[string][]time.Duration{}
+    for _, l := range r.Latencies {
+        values[l.Stage] = append(values[l.Stage], l.Value)
+    }
+    b.WriteString("| Stage | Samples | P50 | P90 | P99 | Max |\n")
+    b.WriteString("|---|--:|--:|--:|--:|--:|\n")
+    for _, s := range stages {
+        sum := analysis.Summarize(values[s.Name])
+        if sum.Count == 0 {
+            fmt.Fprintf(&b, "| %s | 0 | – | – | – | – |\n", markdownEscape(s.Name))
+            continue
+        }
+        fmt.Fprintf(&b, "| %s | %d | %s | %s | %s | %s |\n", markdownEscape(s.Name), sum.Count,
+            formatSeconds(sum.P50.Seconds()), formatSeconds(sum.P90.Seconds()),
+            formatSeconds(sum.P99.Seconds()), formatSeconds(sum.Max.Seconds()))
+    }
+
+    if len(checks) > 0 {
+        b.WriteString("\n| | Check | Measured | Threshold |\n|---|---|---|---|\n")
+        for _, c := range checks {
+            mark := "✅"
+            if !c.Passed {
+                mark = "❌"
+            }
+            fmt.Fprintf(&b, "| %s | %s: %s | %s | %s |\n", mark, c.Suite, markdownEscape(c.Name),
+                markdownEscape(c.Measured), markdownEscape(c.Threshold))
+        }
+    }
+
+    fmt.Fprintf(&b, "\n<details><summary>Data quality: %d warnings</summary>\n\n", len(r.Warnings))
+    b.WriteString("| Stage | Objects | Samples | Missing | Negative | Ties <1s |\n|---|--:|--:|--:|--:|--:|\n")
+    for _, q := range r.Quality {
+        fmt.Fprintf(&b, "| %s | %d | %d | %d | %d | %d |\n", markdownEscape(q.Stage), q.Objects, q.Samples, q.MissingTotal(), q.Negative, q.Ties)
+    }
+    if len(r.Warnings) > 0 {
+        b.WriteString("\n")
+    }
+    for _, warning := range r.Warnings {
+        fmt.Fprintf(&b, "- %s\n", markdownEscape(warning))
+    }
+    b.WriteString("\n</details>\n")
+
+    _, err := io.WriteString(w, b.String())
+    return err
+}
+
+// markdownEscape keeps s from breaking out of a table cell.
+func markdownEscape(s string) string {
+    s = strings.ReplaceAll(s, "|", `\|`)
+    return strings.ReplaceAll(s, "\n", " ")
+}
+
+// WriteMarkdown writes the Markdown summary of r to path, creating its
+// directory.
+func WriteMarkdown(path string, r *analysis.Result, stages []analysis.Stage, checks []analysis.Check) error {
+    if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+        return err
+    }
+    f, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    if err := Markdown(f, r, stages, checks); err != nil {
+        f.Close()
+        return err
+    }
+    return f.Close()
+}
@@ -0,0 +1,28 @@
+package report
+
+import (
+    "os"
+    "path/filepath"
+    "testing"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+)
+
+func TestWritersCreateDirectory(t *testing.T) {
+    r := &analysis.Result{Run: analysis.Run{ID: "run"}}
+    dir := filepath.Join(t.TempDir(), "out", "sub")
+    for name, write := range map[string]func(string) error{
+        "report.md":   func(path string) error { return WriteMarkdown(path, r, analysis.DefaultStages, nil) },
+        "junit.xml":   func(path string) error { return WriteJUnit(path, r, nil) },
+        "report.html": func(path string) error { return WriteHTML(path, r, analysis.DefaultStages) },
+    } {
+        path := filepath.Join(dir, name)
+        if err := write(path); err != nil {
+            t.Errorf("%s: %v", name, err)
+            continue
+        }
+        if _, err := os.Stat(path); err != nil {
+            t.Errorf("%s: %v", name, err)
+        }
+    }
+}
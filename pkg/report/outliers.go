@@ -0,0 +1,139 @@
+package report
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "sort"
+    "strings"
+    "text/tabwriter"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+)
+
+const timelineFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// Outliers writes the outlier limits of every stage, then a drill-down of
+// up to max stragglers, worst first: their full event timeline with the
+// phase each step belongs to, the managedFields writers of every related
+// object and the ManifestWork they rode in. max <= 0 shows all of them.
+func Outliers(w io.Writer, o analysis.OutlierReport, max int) error {
+    var b strings.Builder
+    title := fmt.Sprintf("Outliers (%s)", o.Rule)
+    fmt.Fprintf(&b, "%s\n%s\n", title, strings.Repeat("-", len(title)))
+    tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(tw, "Stage\tSamples\tMedian\tLimit\tOutliers")
+    for _, s := range o.Stages {
+        limit := "-"
+        if s.Limit > 0 {
+            limit = s.Limit.Round(time.Millisecond).String()
+        }
+        fmt.Fprintf(tw, "%s\t%d\t%v\t%s\t%d\n", s.Stage, s.Samples, s.Median.Round(time.Millisecond), limit, s.Outliers)
+    }
+    tw.Flush()
+
+    shown := o.Stragglers
+    if max > 0 && len(shown) > max {
+        shown = shown[:max]
+    }
+    if len(o.Stragglers) > 0 {
+        fmt.Fprintf(&b, "\nStragglers (%d of %d, worst first)\n", len(shown), len(o.Stragglers))
+    }
+    for _, s := range shown {
+        b.WriteString("\n")
+        straggler(&b, s)
+    }
+
+    _, err := io.WriteString(w, b.String())
+    return err
+}
+
+// straggler writes the drill-down of one straggler.
+func straggler(b *strings.Builder, s analysis.Straggler) {
+    work := s.ManifestWork
+    if work == "" {
+        work = "unknown"
+    }
+    fmt.Fprintf(b, "%s %s/%s on %s, ManifestWork %s\n", s.Kind, s.Namespace, s.Name, s.Cluster, work)
+    for _, o := range s.Outliers {
+        fmt.Fprintf(b, "  %s: %v, limit %v\n", o.Stage, o.Value.Round(time.Millisecond), o.Limit.Round(time.Millisecond))
+    }
+
+    type step struct {
+        event analysis.Event
+        at    time.Time
+    }
+    var steps []step
+    for e, t := range s.Events {
+        if !t.IsZero() {
+            steps = append(steps, step{e, t})
+        }
+    }
+    sort.Slice(steps, func(i, j int) bool {
+        if !steps[i].at.Equal(steps[j].at) {
+            return steps[i].at.Before(steps[j].at)
+        }
+        return steps[i].event.String() < steps[j].event.String()
+    })
+
+    // The step after the longest gap is where the object was held up.
+    slowest, gap := 0, time.Duration(0)
+    for i := 1; i < len(steps); i++ {
+        if d := steps[i].at.Sub(steps[i-1].at); d > gap {
+            slowest, gap = i, d
+        }
+    }
+
+    b.WriteString("  Timeline:\n")
+    tw := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+    for i, st := range steps {
+        row := []string{"    " + st.at.Format(timelineFormat), st.event.String()}
+        if i > 0 {
+            row = append(row, "+"+st.at.Sub(steps[i-1].at).Round(time.Millisecond).String(), analysis.Phase(st.event))
+        }
+        if i == slowest && slowest > 0 {
+            row = append(row, "<- slowest step")
+        }
+        fmt.Fprintln(tw, strings.TrimRight(strings.Join(row, "\t"), "\t"))
+    }
+    tw.Flush()
+    if slowest > 0 {
+        phase := analysis.Phase(steps[slowest].event)
+        if phase == "" {
+            phase = "unattributed"
+        }
+        fmt.Fprintf(b, "  Slowest step: %v before %s (%s)\n", gap.Round(time.Millisecond), steps[slowest].event, phase)
+    }
+
+    if len(s.Writes) > 0 {
+        b.WriteString("  Writers:\n")
+        tw = tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+        for _, w := range s.Writes {
+            operation := w.Operation
+            if w.Subresource != "" {
+                operation += " " + w.Subresource
+            }
+            at := "-"
+            if !w.Time.IsZero() {
+                at = w.Time.Format(timelineFormat)
+            }
+            fmt.Fprintf(tw, "    %s\t%s/%s\t%s\t%s\n", at, w.Role, w.Kind, w.Manager, operation)
+        }
+        tw.Flush()
+    }
+}
+
+// WriteOutliers writes the Outliers report of o with every straggler to
+// path.
+func WriteOutliers(path string, o analysis.OutlierReport) error {
+    f, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    if err := Outliers(f, o, 0); err != nil {
+        f.Close()
+        return err
+    }
+    return f.Close()
+}
@@ -0,0 +1,97 @@
+package report
+
+import (
+    _ "embed"
+    "fmt"
+    "html/template"
+    "io"
+    "math"
+    "os"
+    "slices"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+)
+
+//go:embed scaling.html
+var scalingSource string
+
+var scalingTemplate = template.Must(template.New("scaling").Funcs(template.FuncMap{
+    "seconds": formatSeconds,
+    "metric": func(p analysis.ScalingPoint, stage, metric string) string {
+        if v, ok := p.Metric(stage, metric); ok {
+            return formatSeconds(v)
+        }
+        return "–"
+    },
+    "float": func(v float64) string { return fmt.Sprintf("%.2f", v) },
+}).Parse(scalingSource))
+
+// ScalingMetrics are the stage statistics charted and fitted in a sweep.
+var ScalingMetrics = []string{"p50", "p99"}
+
+type scalingChart struct {
+    Metric    string
+    Parameter string
+    Fixed     string
+    Chart     template.HTML
+}
+
+type scalingData struct {
+    Points []analysis.ScalingPoint
+    Stages []string
+    Charts []scalingChart
+    Trends []analysis.Trend
+}
+
+// Scaling writes a self-contained HTML page of a sweep: for every swept
+// parameter, a chart per metric of each stage's latency against it with the
+// other parameters held fixed, then the fitted trends and the scaling table.
+func Scaling(w io.Writer, points []analysis.ScalingPoint, trends []analysis.Trend) error {
+    data := scalingData{Points: points, Trends: trends}
+    for _, p := range points {
+        for _, s := range p.Stages {
+            if !slices.Contains(data.Stages, s.Stage) {
+                data.Stages = append(data.Stages, s.Stage)
+            }
+        }
+    }
+
+    for _, sl := range analysis.Slices(points) {
+        for _, metric := range ScalingMetrics {
+            var lines []line
+            xMax, yMax := 0.0, 0.0
+            for i, stage := range data.Stages {
+                l := line{Name: stage, Color: palette[i%len(palette)], Markers: true}
+                for _, p := range sl.Points {
+                    if v, ok := p.Metric(stage, metric); ok {
+                        x := float64(p.Parameter(sl.Parameter))
+                        l.Points = append(l.Points, [2]float64{x, v})
+                        xMax = math.Max(xMax, x)
+                        yMax = math.Max(yMax, v)
+                    }
+                }
+                if len(l.Points) > 0 {
+                    lines = append(lines, l)
+                }
+            }
+            chart := lineChart(lines, axes{xMax: nonZero(xMax), yMax: nonZero(yMax), xFormat: func(v float64) string { return fmt.Sprintf("%.0f", v) }},
+                sl.Parameter, metric+" latency", formatSeconds)
+            data.Charts = append(data.Charts, scalingChart{Metric: metric, Parameter: sl.Parameter, Fixed: sl.Fixed, Chart: chart})
+        }
+    }
+
+    return scalingTemplate.Execute(w, data)
+}
+
+// WriteScaling writes the sweep page to path.
+func WriteScaling(path string, points []analysis.ScalingPoint, trends []analysis.Trend) error {
+    f, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    if err := Scaling(f, points, trends); err != nil {
+        f.Close()
+        return err
+    }
+    return f.Close()
+}
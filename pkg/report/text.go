@@ -0,0 +1,104 @@
+package report
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "strings"
+    "text/tabwriter"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+)
+
+// Text writes the stages of one lifecycle of r as latency_results.txt: every
+// timestamp the stages use, each stage under its group with the formula it
+// was computed with, and the data quality of every stage across the run.
+// The console report prints the same text.
+func Text(w io.Writer, r *analysis.Result, l analysis.Lifecycle) error {
+    stages := r.Stages
+    var b strings.Builder
+    fmt.Fprintf(&b, "KubeStellar Performance Metrics\n")
+    fmt.Fprintf(&b, "Object: %s %s/%s on %s\n", l.Kind, l.Namespace, l.Name, l.Cluster)
+    fmt.Fprintf(&b, "Every Timestamp used in below formulas:\n\n")
+
+    tw := tabwriter.NewWriter(&b, 0, 0, 1, ' ', 0)
+    seen := map[analysis.Event]bool{}
+    for _, s := range stages {
+        for _, e := range []analysis.Event{s.Start, s.End} {
+            if seen[e] {
+                continue
+            }
+            seen[e] = true
+            value := "missing"
+            if t, ok := l.Time(e); ok {
+                value = t.Format(time.RFC3339Nano)
+            }
+            fmt.Fprintf(tw, "%s:\t%s\n", e, value)
+        }
+    }
+    tw.Flush()
+
+    b.WriteString("\n================================")
+    tw = tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+    for i, s := range stages {
+        if i == 0 || s.Group != stages[i-1].Group {
+            tw.Flush()
+            title := s.Group + " Metrics"
+            if s.Group == "" {
+                title = "Other Metrics"
+            }
+            fmt.Fprintf(&b, "\n%s\n%s\n", title, strings.Repeat("-", len(title)))
+        }
+        fmt.Fprintf(tw, "%s:\t%s\t%s − %s\n", s.Name, stageValue(l, s), s.End, s.Start)
+    }
+    tw.Flush()
+
+    fmt.Fprintf(&b, "\nData Quality (all %d objects)\n", len(r.Lifecycles))
+    b.WriteString("----------------------------\n")
+    tw = tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(tw, "Stage\tSamples\tMissing\tNegative\tTies <1s")
+    for _, q := range r.Quality {
+        fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\n", q.Stage, q.Samples, q.MissingTotal(), q.Negative, q.Ties)
+    }
+    tw.Flush()
+    for _, warning := range r.Warnings {
+        fmt.Fprintf(&b, "! %s\n", warning)
+    }
+
+    _, err := io.WriteString(w, b.String())
+    return err
+}
+
+// stageValue renders one stage of l, or why it cannot be measured.
+func stageValue(l analysis.Lifecycle, s analysis.Stage) string {
+    start, ok := l.Time(s.Start)
+    if !ok {
+        return "N/A (missing " + s.Start.String() + ")"
+    }
+    end, ok := l.Time(s.End)
+    if !ok {
+        return "N/A (missing " + s.End.String() + ")"
+    }
+    d := end.Sub(start)
+    if d < 0 {
+        return fmt.Sprintf("N/A (negative, %v)", d.Round(time.Millisecond))
+    }
+    if d == 0 && (start.Nanosecond() == 0 || end.Nanosecond() == 0) {
+        return "<1s (same second)"
+    }
+    return d.Round(time.Millisecond).String()
+}
+
+// WriteText writes the Text report of l to path.
+func WriteText(path string, r *analysis.Result, l analysis.Lifecycle) error {
+    f, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    if err := Text(f, r, l); err != nil {
+        f.Close()
+        return err
+    }
+    return f.Close()
+}
@@ -0,0 +1,133 @@
+package store
+
+import (
+    "os"
+    "path/filepath"
+    "strconv"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+    "github.com/asmit27rai/collector/pkg/collector"
+    "github.com/parquet-go/parquet-go"
+    "github.com/parquet-go/parquet-go/compress/zstd"
+)
+
+// latencyRow is one stage measured on one object, as written to
+// latencies.parquet.
+type latencyRow struct {
+    RunID     string        `parquet:"run_id,dict"`
+    Stage     string        `parquet:"stage,dict"`
+    Kind      string        `parquet:"kind,dict"`
+    Namespace string        `parquet:"namespace,dict"`
+    Name      string        `parquet:"name"`
+    Cluster   string        `parquet:"cluster,dict"`
+    Start     time.Time     `parquet:"start,timestamp(nanosecond:utc)"`
+    End       time.Time     `parquet:"end,timestamp(nanosecond:utc)"`
+    Latency   time.Duration `parquet:"latency_ns"`
+    Seconds   float64       `parquet:"seconds"`
+}
+
+// stageRow is one raw timestamp of one collected object, as written to
+// stage_timestamps.parquet.
+type stageRow struct {
+    RunID     string    `parquet:"run_id,dict"`
+    Role      string    `parquet:"role,dict"`
+    Cluster   string    `parquet:"cluster,dict"`
+    Kind      string    `parquet:"kind,dict"`
+    Namespace string    `parquet:"namespace,dict"`
+    Name      string    `parquet:"name"`
+    UID       string    `parquet:"uid"`
+    Stage     string    `parquet:"stage,dict"`
+    At        time.Time `parquet:"at,timestamp(nanosecond:utc)"`
+}
+
+// WriteParquet writes latencies.parquet and stage_timestamps.parquet for r
+// into dir. Both files carry the run's metadata as key/value metadata.
+func WriteParquet(dir string, r *analysis.Result) error {
+    if err := os.MkdirAll(dir, 0755); err != nil {
+        return err
+    }
+
+    var latencies []latencyRow
+    for _, l := range r.Latencies {
+        latencies = append(latencies, latencyRow{
+            RunID:     r.Run.ID,
+            Stage:     l.Stage,
+            Kind:      l.Kind,
+            Namespace: l.Namespace,
+            Name:      l.Name,
+            Cluster:   l.Cluster,
+            Start:     l.Start,
+            End:       l.End,
+            Latency:   l.Value,
+            Seconds:   l.Value.Seconds(),
+        })
+    }
+
+    var timestamps []stageRow
+    add := func(role collector.Role, cluster, kind, namespace, name, uid string, stages map[string]time.Time) {
+        for stage, at := range stages {
+            timestamps = append(timestamps, stageRow{
+                RunID:     r.Run.ID,
+                Role:      string(role),
+                Cluster:   cluster,
+                Kind:      kind,
+                Namespace: namespace,
+                Name:      name,
+                UID:       uid,
+                Stage:     stage,
+                At:        at,
+            })
+        }
+    }
+    for _, m := range r.Dataset.Objects {
+        add(m.Role, m.Cluster, m.Kind, m.Namespace, m.Name, m.UID, m.Stages)
+    }
+    for _, w := range r.Dataset.Works {
+        add(w.Role, w.Cluster, w.Kind, w.Namespace, w.Name, w.UID, w.Stages)
+    }
+
+    if err := writeParquetFile(filepath.Join(dir, "latencies.parquet"), r.Run, latencies); err != nil {
+        return err
+    }
+    return writeParquetFile(filepath.Join(dir, "stage_timestamps.parquet"), r.Run, timestamps)
+}
+
+func writeParquetFile[T any](path string, run analysis.Run, rows []T) error {
+    f, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+
+    options := []parquet.WriterOption{parquet.Compression(&zstd.Codec{})}
+    for key, value := range runMetadata(run) {
+        options = append(options, parquet.KeyValueMetadata(key, value))
+    }
+
+    w := parquet.NewGenericWriter[T](f, options...)
+    if _, err := w.Write(rows); err != nil {
+        return err
+    }
+    if err := w.Close(); err != nil {
+        return err
+    }
+    return f.Close()
+}
+
+func runMetadata(run analysis.Run) map[string]string {
+    finished := ""
+    if !run.Finished.IsZero() {
+        finished = run.Finished.UTC().Format(time.RFC3339Nano)
+    }
+    return map[string]string{
+        "kubestellar.run.id":          run.ID,
+        "kubestellar.run.started":     run.Started.UTC().Format(time.RFC3339Nano),
+        "kubestellar.run.finished":    finished,
+        "kubestellar.run.wds_context": run.WDSContext,
+        "kubestellar.run.its_context": run.ITSContext,
+        "kubestellar.run.wec_context": run.WECContext,
+        "kubestellar.run.num_ns":      strconv.Itoa(run.NumNS),
+        "kubestellar.run.complete":    strconv.FormatBool(run.Complete),
+    }
+}
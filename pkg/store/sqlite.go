@@ -0,0 +1,270 @@
+package store
+
+import (
+    "database/sql"
+    "fmt"
+    "net/url"
+    "os"
+    "path/filepath"
+    "time"
+
+    "github.com/asmit27rai/collector/pkg/analysis"
+    "github.com/asmit27rai/collector/pkg/collector"
+    _ "modernc.org/sqlite"
+)
+
+// schema normalises runs into clusters, the objects listed from them, each
+// object's stage timestamps, and the latencies computed per lifecycle. Times
+// are stored both as RFC 3339 text and as Unix nanoseconds.
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+    id          TEXT PRIMARY KEY,
+    started     TEXT NOT NULL,
+    finished    TEXT,
+    output_dir  TEXT,
+    num_ns      INTEGER,
+    complete    INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS clusters (
+    id       INTEGER PRIMARY KEY,
+    run_id   TEXT NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+    role     TEXT NOT NULL,
+    context  TEXT NOT NULL,
+    UNIQUE (run_id, role)
+);
+CREATE TABLE IF NOT EXISTS objects (
+    id          INTEGER PRIMARY KEY,
+    run_id      TEXT NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+    cluster_id  INTEGER NOT NULL REFERENCES clusters(id) ON DELETE CASCADE,
+    kind        TEXT NOT NULL,
+    namespace   TEXT NOT NULL,
+    name        TEXT NOT NULL,
+    uid         TEXT,
+    condition   TEXT,
+    manager     TEXT,
+    status      TEXT,
+    target      TEXT,
+    binding     TEXT
+);
+CREATE INDEX IF NOT EXISTS objects_by_name ON objects (run_id, kind, namespace, name);
+CREATE TABLE IF NOT EXISTS stage_timestamps (
+    object_id  INTEGER NOT NULL REFERENCES objects(id) ON DELETE CASCADE,
+    stage      TEXT NOT NULL,
+    at         TEXT NOT NULL,
+    at_unix_ns INTEGER NOT NULL,
+    PRIMARY KEY (object_id, stage)
+);
+CREATE TABLE IF NOT EXISTS latencies (
+    run_id     TEXT NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+    stage      TEXT NOT NULL,
+    kind       TEXT NOT NULL,
+    namespace  TEXT NOT NULL,
+    name       TEXT NOT NULL,
+    cluster    TEXT,
+    seconds    REAL NOT NULL
+);
+CREATE INDEX IF NOT EXISTS latencies_by_stage ON latencies (run_id, stage);
+`
+
+// SQLite stores results of many runs in one embedded database file.
+type SQLite struct {
+    db *sql.DB
+}
+
+// OpenSQLite opens (creating if needed) the database at path.
+func OpenSQLite(path string) (*SQLite, error) {
+    dsn, err := fileDSN(path, "")
+    if err != nil {
+        return nil, err
+    }
+    db, err := sql.Open("sqlite", dsn)
+    if err != nil {
+        return nil, err
+    }
+    // One writer at a time; SQLite serialises them anyway.
+    db.SetMaxOpenConns(1)
+
+    if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
+        db.Close()
+        return nil, err
+    }
+    if _, err := db.Exec(schema); err != nil {
+        db.Close()
+        return nil, fmt.Errorf("failed to create schema: %v", err)
+    }
+    return &SQLite{db: db}, nil
+}
+
+// OpenSQLiteReadOnly opens an existing database at path for querying only.
+func OpenSQLiteReadOnly(path string) (*SQLite, error) {
+    if _, err := os.Stat(path); err != nil {
+        return nil, err
+    }
+    dsn, err := fileDSN(path, "mode=ro")
+    if err != nil {
+        return nil, err
+    }
+    db, err := sql.Open("sqlite", dsn)
+    if err != nil {
+        return nil, err
+    }
+    return &SQLite{db: db}, nil
+}
+
+// fileDSN builds the file: URI of the database at path. Building it as a
+// URL escapes any ? or # in the path that would otherwise start the query;
+// the path is made absolute so a relative one is not taken for a host.
+func fileDSN(path, query string) (string, error) {
+    abs, err := filepath.Abs(path)
+    if err != nil {
+        return "", err
+    }
+    dsn := url.URL{Scheme: "file", Path: filepath.ToSlash(abs), RawQuery: query}
+    return dsn.String(), nil
+}
+
+func (s *SQLite) Close() error {
+    return s.db.Close()
+}
+
+// WriteResult stores one run, replacing an earlier copy with the same ID.
+func (s *SQLite) WriteResult(r *analysis.Result) error {
+    tx, err := s.db.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    run := r.Run
+    if _, err := tx.Exec(`DELETE FROM runs WHERE id = ?`, run.ID); err != nil {
+        return err
+    }
+    _, err = tx.Exec(`INSERT INTO runs (id, started, finished, output_dir, num_ns, complete) VALUES (?, ?, ?, ?, ?, ?)`,
+        run.ID, formatTime(run.Started), formatTime(run.Finished), run.OutputDir, run.NumNS, run.Complete)
+    if err != nil {
+        return err
+    }
+
+    clusters := map[collector.Role]int64{}
+    for role, context := range map[collector.Role]string{
+        collector.RoleWDS: run.WDSContext,
+        collector.RoleITS: run.ITSContext,
+        collector.RoleWEC: run.WECContext,
+    } {
+        res, err := tx.Exec(`INSERT INTO clusters (run_id, role, context) VALUES (?, ?, ?)`, run.ID, string(role), context)
+        if err != nil {
+            return err
+        }
+        if clusters[role], err = res.LastInsertId(); err != nil {
+            return err
+        }
+    }
+
+    insertObject, err := tx.Prepare(`INSERT INTO objects (run_id, cluster_id, kind, namespace, name, uid, condition, manager, status, target, binding)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+    if err != nil {
+        return err
+    }
+    defer insertObject.Close()
+    insertStage, err := tx.Prepare(`INSERT OR REPLACE INTO stage_timestamps (object_id, stage, at, at_unix_ns) VALUES (?, ?, ?, ?)`)
+    if err != nil {
+        return err
+    }
+    defer insertStage.Close()
+
+    addStages := func(id int64, stages map[string]time.Time) error {
+        for stage, t := range stages {
+            if _, err := insertStage.Exec(id, stage, formatTime(t), t.UnixNano()); err != nil {
+                return err
+            }
+        }
+        return nil
+    }
+
+    for _, m := range r.Dataset.Objects {
+        res, err := insertObject.Exec(run.ID, clusters[m.Role], m.Kind, m.Namespace, m.Name, m.UID, m.Condition, m.Manager, "", "", "")
+        if err != nil {
+            return err
+        }
+        id, err := res.LastInsertId()
+        if err != nil {
+            return err
+        }
+        if err := addStages(id, m.Stages); err != nil {
+            return err
+        }
+    }
+    for _, w := range r.Dataset.Works {
+        res, err := insertObject.Exec(run.ID, clusters[w.Role], w.Kind, w.Namespace, w.Name, w.UID, "", "", w.Status, w.TargetObject, w.Binding)
+        if err != nil {
+            return err
+        }
+        id, err := res.LastInsertId()
+        if err != nil {
+            return err
+        }
+        if err := addStages(id, w.Stages); err != nil {
+            return err
+        }
+    }
+
+    insertLatency, err := tx.Prepare(`INSERT INTO latencies (run_id, stage, kind, namespace, name, cluster, seconds) VALUES (?, ?, ?, ?, ?, ?, ?)`)
+    if err != nil {
+        return err
+    }
+    defer insertLatency.Close()
+    for _, l := range r.Latencies {
+        if _, err := insertLatency.Exec(run.ID, l.Stage, l.Kind, l.Namespace, l.Name, l.Cluster, l.Value.Seconds()); err != nil {
+            return err
+        }
+    }
+
+    return tx.Commit()
+}
+
+// Query runs a SQL statement and returns its column names and
+// rows rendered as text.
+func (s *SQLite) Query(query string, args ...interface{}) ([]string, [][]string, error) {
+    rows, err := s.db.Query(query, args...)
+    if err != nil {
+        return nil, nil, err
+    }
+    defer rows.Close()
+
+    columns, err := rows.Columns()
+    if err != nil {
+        return nil, nil, err
+    }
+
+    var out [][]string
+    values := make([]interface{}, len(columns))
+    ptrs := make([]interface{}, len(columns))
+    for i := range values {
+        ptrs[i] = &values[i]
+    }
+    for rows.Next() {
+        if err := rows.Scan(ptrs...); err != nil {
+            return nil, nil, err
+        }
+        row := make([]string, len(columns))
+        for i, v := range values {
+            switch v := v.(type) {
+            case nil:
+                row[i] = "NULL"
+            case []byte:
+                row[i] = string(v)
+            default:
+                row[i] = fmt.Sprint(v)
+            }
+        }
+        out = append(out, row)
+    }
+    return columns, out, rows.Err()
+}
+
+func formatTime(t time.Time) interface{} {
+    if t.IsZero() {
+        return nil
+    }
+    return t.UTC().Format(time.RFC3339Nano)
+}
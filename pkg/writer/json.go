@@ -0,0 +1,98 @@
+package writer
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/json"
+)
+
+// JSONSink writes each table as a JSON array of objects keyed by column.
+type JSONSink struct {
+    Root string
+}
+
+func (s *JSONSink) Write(t Table) error {
+    f, err := create(s.Root, t.Path, ".json")
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+
+    records := make([]orderedRecord, 0, len(t.Rows))
+    for _, row := range t.Rows {
+        records = append(records, record(t.Columns, row))
+    }
+
+    enc := json.NewEncoder(f)
+    enc.SetIndent("", "  ")
+    if err := enc.Encode(records); err != nil {
+        return err
+    }
+    return f.Close()
+}
+
+func (s *JSONSink) Close() error { return nil }
+
+// NDJSONSink writes each table as newline-delimited JSON, one object per row.
+type NDJSONSink struct {
+    Root string
+}
+
+func (s *NDJSONSink) Write(t Table) error {
+    f, err := create(s.Root, t.Path, ".ndjson")
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+
+    w := bufio.NewWriter(f)
+    enc := json.NewEncoder(w)
+    for _, row := range t.Rows {
+        if err := enc.Encode(record(t.Columns, row)); err != nil {
+            return err
+        }
+    }
+    if err := w.Flush(); err != nil {
+        return err
+    }
+    return f.Close()
+}
+
+func (s *NDJSONSink) Close() error { return nil }
+
+// orderedRecord is one row as a JSON object whose keys keep column order.
+type orderedRecord struct {
+    columns []string
+    values  []string
+}
+
+func record(columns, row []string) orderedRecord {
+    return orderedRecord{columns: columns, values: row}
+}
+
+func (r orderedRecord) MarshalJSON() ([]byte, error) {
+    var buf bytes.Buffer
+    buf.WriteByte('{')
+    for i, col := range r.columns {
+        if i > 0 {
+            buf.WriteByte(',')
+        }
+        key, err := json.Marshal(col)
+        if err != nil {
+            return nil, err
+        }
+        value := ""
+        if i < len(r.values) {
+            value = r.values[i]
+        }
+        val, err := json.Marshal(value)
+        if err != nil {
+            return nil, err
+        }
+        buf.Write(key)
+        buf.WriteByte(':')
+        buf.Write(val)
+    }
+    buf.WriteByte('}')
+    return buf.Bytes(), nil
+}
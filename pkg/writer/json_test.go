@@ -0,0 +1,115 @@
+package writer
+
+import (
+    "bufio"
+    "encoding/json"
+    "io"
+    "os"
+    "path/filepath"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+// keyOrder decodes one JSON object from dec, returning its keys in the
+// order they appear and its values.
+func keyOrder(t *testing.T, dec *json.Decoder) ([]string, map[string]string) {
+    t.Helper()
+    if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+        t.Fatalf("want an object, got %v (%v)", tok, err)
+    }
+    var keys []string
+    values := map[string]string{}
+    for dec.More() {
+        key, err := dec.Token()
+        if err != nil {
+            t.Fatal(err)
+        }
+        var value string
+        if err := dec.Decode(&value); err != nil {
+            t.Fatal(err)
+        }
+        keys = append(keys, key.(string))
+        values[key.(string)] = value
+    }
+    if _, err := dec.Token(); err != nil {
+        t.Fatal(err)
+    }
+    return keys, values
+}
+
+// ordered has columns in an order that is neither sorted nor reversed, so
+// a map-based encoding would show.
+var ordered = Table{
+    Path:    "perf-test-0/deployments-wds/deployments",
+    Columns: []string{"Name", "Cluster", "UID", "Kind", "Stages"},
+    Rows: [][]string{
+        {"nginx", "wds1", "u-1", "deployments", "created=2025-05-26T15:00:00.5Z"},
+        {"quo\"te\n", "wds1", "u-2", "deployments", ""},
+    },
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+    root := t.TempDir()
+    if err := (&JSONSink{Root: root}).Write(ordered); err != nil {
+        t.Fatal(err)
+    }
+    f, err := os.Open(filepath.Join(root, ordered.Path+".json"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer f.Close()
+
+    dec := json.NewDecoder(f)
+    if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+        t.Fatalf("want an array, got %v (%v)", tok, err)
+    }
+    for _, row := range ordered.Rows {
+        keys, values := keyOrder(t, dec)
+        checkRecord(t, keys, values, row)
+    }
+    if dec.More() {
+        t.Error("more records than rows")
+    }
+}
+
+func TestNDJSONRoundTrip(t *testing.T) {
+    root := t.TempDir()
+    if err := (&NDJSONSink{Root: root}).Write(ordered); err != nil {
+        t.Fatal(err)
+    }
+    data, err := os.ReadFile(filepath.Join(root, ordered.Path+".ndjson"))
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    // One object per line, with the line breaks inside values escaped.
+    scanner := bufio.NewScanner(strings.NewReader(string(data)))
+    var lines int
+    for ; scanner.Scan(); lines++ {
+        if lines >= len(ordered.Rows) {
+            t.Fatalf("more lines than rows:\n%s", data)
+        }
+        dec := json.NewDecoder(strings.NewReader(scanner.Text()))
+        keys, values := keyOrder(t, dec)
+        checkRecord(t, keys, values, ordered.Rows[lines])
+        if _, err := dec.Token(); err != io.EOF {
+            t.Errorf("line %d has more than one object", lines+1)
+        }
+    }
+    if lines != len(ordered.Rows) {
+        t.Errorf("%d lines, want %d", lines, len(ordered.Rows))
+    }
+}
+
+func checkRecord(t *testing.T, keys []string, values map[string]string, row []string) {
+    t.Helper()
+    if !reflect.DeepEqual(keys, ordered.Columns) {
+        t.Errorf("keys = %q, want column order %q", keys, ordered.Columns)
+    }
+    for i, col := range ordered.Columns {
+        if values[col] != row[i] {
+            t.Errorf("%s = %q, want %q", col, values[col], row[i])
+        }
+    }
+}
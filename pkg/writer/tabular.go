@@ -0,0 +1,226 @@
+package writer
+
+import (
+    "bufio"
+    "encoding/csv"
+    "fmt"
+    "io"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+)
+
+// SchemaVersion identifies the column layout of the tabular files. TSV
+// files carry it as a "#schema=N" line ahead of the column header; CSV
+// files, which tools expect to start with the header, have it in a
+// SchemaFile next to them. Readers reject any other version. Version 1 was the unversioned layout without
+// cluster, kind, namespace and UID columns; version 2 had second-precision
+// times and no Stages column.
+const SchemaVersion = 3
+
+// SchemaFile is the file below a CSV sink's root recording SchemaVersion.
+const SchemaFile = "schema.txt"
+
+var schemaLine = fmt.Sprintf("#schema=%d", SchemaVersion)
+
+var (
+    tsvEscaper   = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+    tsvUnescaper = strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n", `\r`, "\r")
+)
+
+// TSVSink writes each table as tab-separated text: a schema line, a column
+// header, then one line per row. Backslashes, tabs and line breaks inside
+// values are escaped as \\, \t, \n and \r so every row stays on one line.
+type TSVSink struct {
+    Root string
+}
+
+func (s *TSVSink) Write(t Table) error {
+    f, err := create(s.Root, t.Path, ".tsv")
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+
+    w := bufio.NewWriter(f)
+    w.WriteString(schemaLine + "\n")
+    w.WriteString(tsvLine(t.Columns))
+    for _, row := range t.Rows {
+        w.WriteString(tsvLine(row))
+    }
+    if err := w.Flush(); err != nil {
+        return err
+    }
+    return f.Close()
+}
+
+func (s *TSVSink) Close() error { return nil }
+
+func tsvLine(values []string) string {
+    escaped := make([]string, len(values))
+    for i, v := range values {
+        escaped[i] = tsvEscaper.Replace(v)
+    }
+    return strings.Join(escaped, "\t") + "\n"
+}
+
+// ReadTSV loads a table written by TSVSink, rejecting files of a different
+// schema version.
+func ReadTSV(path string) (Table, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return Table{}, err
+    }
+    defer f.Close()
+
+    scanner := bufio.NewScanner(f)
+    scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+    if !scanner.Scan() {
+        if err := scanner.Err(); err != nil {
+            return Table{}, err
+        }
+        return Table{}, fmt.Errorf("%s is empty", path)
+    }
+    if err := checkSchema(path, scanner.Text(), "#schema="); err != nil {
+        return Table{}, err
+    }
+
+    if !scanner.Scan() {
+        if err := scanner.Err(); err != nil {
+            return Table{}, err
+        }
+        return Table{}, fmt.Errorf("%s has no column header", path)
+    }
+    t := Table{Columns: splitTSV(scanner.Text())}
+
+    for scanner.Scan() {
+        row := splitTSV(scanner.Text())
+        if len(row) != len(t.Columns) {
+            return Table{}, fmt.Errorf("%s: row %d has %d fields, want %d", path, len(t.Rows)+1, len(row), len(t.Columns))
+        }
+        t.Rows = append(t.Rows, row)
+    }
+    return t, scanner.Err()
+}
+
+func splitTSV(line string) []string {
+    fields := strings.Split(line, "\t")
+    for i, f := range fields {
+        fields[i] = tsvUnescaper.Replace(f)
+    }
+    return fields
+}
+
+// checkSchema rejects the first line of a schema-versioned file unless it
+// is prefix followed by SchemaVersion.
+func checkSchema(path, line, prefix string) error {
+    if line == fmt.Sprintf("%s%d", prefix, SchemaVersion) {
+        return nil
+    }
+    version := strings.TrimPrefix(line, prefix)
+    if version == line {
+        version = "none (version 1)"
+    }
+    return fmt.Errorf("%s: unsupported table schema %s, want %d; re-collect with this collector version", path, version, SchemaVersion)
+}
+
+// CSVSink writes each table as RFC 4180 comma-separated values starting
+// with the column header. The schema version goes to SchemaFile once.
+type CSVSink struct {
+    Root string
+
+    schemaOnce sync.Once
+    schemaErr  error
+}
+
+func (s *CSVSink) Write(t Table) error {
+    s.schemaOnce.Do(func() {
+        if s.schemaErr = os.MkdirAll(s.Root, 0755); s.schemaErr == nil {
+            s.schemaErr = os.WriteFile(filepath.Join(s.Root, SchemaFile), []byte(fmt.Sprintf("schema=%d\n", SchemaVersion)), 0644)
+        }
+    })
+    if s.schemaErr != nil {
+        return s.schemaErr
+    }
+
+    f, err := create(s.Root, t.Path, ".csv")
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+
+    w := csv.NewWriter(f)
+    w.UseCRLF = true
+    if err := w.Write(t.Columns); err != nil {
+        return err
+    }
+    if err := w.WriteAll(t.Rows); err != nil {
+        return err
+    }
+    return f.Close()
+}
+
+func (s *CSVSink) Close() error { return nil }
+
+// ReadCSV loads a table written by CSVSink below root, rejecting it unless
+// root's SchemaFile records this schema version.
+func ReadCSV(root, path string) (Table, error) {
+    if err := checkSchemaFile(root); err != nil {
+        return Table{}, err
+    }
+    f, err := os.Open(path)
+    if err != nil {
+        return Table{}, err
+    }
+    defer f.Close()
+
+    records, err := csv.NewReader(f).ReadAll()
+    if err != nil {
+        return Table{}, fmt.Errorf("%s: %v", path, err)
+    }
+    if len(records) == 0 {
+        return Table{}, fmt.Errorf("%s has no column header", path)
+    }
+    return Table{Columns: records[0], Rows: records[1:]}, nil
+}
+
+func checkSchemaFile(root string) error {
+    path := filepath.Join(root, SchemaFile)
+    data, err := os.ReadFile(path)
+    if os.IsNotExist(err) {
+        return fmt.Errorf("%s has no %s; its tables predate schema version %d, re-collect with this collector version", root, SchemaFile, SchemaVersion)
+    }
+    if err != nil {
+        return err
+    }
+    return checkSchema(path, strings.TrimSpace(string(data)), "schema=")
+}
+
+// CheckSchema verifies that the tables below root, in whichever formats
+// they were written, have this schema version, so a reader fails on an old
+// layout instead of misreading its columns.
+func CheckSchema(root string) error {
+    if _, err := os.Stat(filepath.Join(root, SchemaFile)); err == nil {
+        if err := checkSchemaFile(root); err != nil {
+            return err
+        }
+    }
+    return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+        if err != nil || d.IsDir() || filepath.Ext(path) != ".tsv" {
+            return err
+        }
+        f, err := os.Open(path)
+        if err != nil {
+            return err
+        }
+        defer f.Close()
+        line, err := bufio.NewReader(f).ReadString('\n')
+        if err != nil && err != io.EOF {
+            return err
+        }
+        return checkSchema(path, strings.TrimSuffix(line, "\n"), "#schema=")
+    })
+}
@@ -0,0 +1,165 @@
+package writer
+
+import (
+    "os"
+    "path/filepath"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+func TestSchemaVersionChecked(t *testing.T) {
+    table := Table{Path: "ns/deployments", Columns: []string{"Name"}, Rows: [][]string{{"a"}}}
+    for _, tc := range []struct {
+        name    string
+        tsv     string
+        csv     string
+        wantErr string
+    }{
+        {"current", "#schema=3\nName\na\n", "schema=3\n", ""},
+        {"old tsv", "#schema=2\nName\na\n", "", "unsupported table schema 2"},
+        {"unversioned tsv", "Name\na\n", "", "unsupported table schema none"},
+        {"old csv", "", "schema=2\n", "unsupported table schema 2"},
+    } {
+        t.Run(tc.name, func(t *testing.T) {
+            root := t.TempDir()
+            path := filepath.Join(root, table.Path)
+            if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+                t.Fatal(err)
+            }
+            if tc.tsv != "" {
+                if err := os.WriteFile(path+".tsv", []byte(tc.tsv), 0644); err != nil {
+                    t.Fatal(err)
+                }
+                if _, err := ReadTSV(path + ".tsv"); !matches(err, tc.wantErr) {
+                    t.Errorf("ReadTSV = %v, want error %q", err, tc.wantErr)
+                }
+            }
+            if tc.csv != "" {
+                if err := os.WriteFile(filepath.Join(root, SchemaFile), []byte(tc.csv), 0644); err != nil {
+                    t.Fatal(err)
+                }
+                if err := os.WriteFile(path+".csv", []byte("Name\r\na\r\n"), 0644); err != nil {
+                    t.Fatal(err)
+                }
+                if _, err := ReadCSV(root, path+".csv"); !matches(err, tc.wantErr) {
+                    t.Errorf("ReadCSV = %v, want error %q", err, tc.wantErr)
+                }
+            }
+            if err := CheckSchema(root); !matches(err, tc.wantErr) {
+                t.Errorf("CheckSchema = %v, want error %q", err, tc.wantErr)
+            }
+        })
+    }
+}
+
+func TestReadCSVNeedsSchemaFile(t *testing.T) {
+    root := t.TempDir()
+    path := filepath.Join(root, "t.csv")
+    if err := os.WriteFile(path, []byte("Name\r\na\r\n"), 0644); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := ReadCSV(root, path); !matches(err, "has no "+SchemaFile) {
+        t.Errorf("ReadCSV = %v, want a missing schema file error", err)
+    }
+}
+
+func matches(err error, want string) bool {
+    if want == "" {
+        return err == nil
+    }
+    return err != nil && strings.Contains(err.Error(), want)
+}
+
+// awkward holds every value the tabular formats have to escape or quote.
+var awkward = Table{
+    Path:    "perf-test-0/configmaps-wds/configmaps",
+    Columns: []string{"Name", "Value"},
+    Rows: [][]string{
+        {"backslash", `C:\dir\`},
+        {"tab", "a\tb"},
+        {"newline", "a\nb"},
+        {"carriage return", "a\r\nb"},
+        {"escape lookalike", `a\tb\n`},
+        {"quote", `say "hi"`},
+        {"comma", "a,b"},
+        {"empty", ""},
+        {"unicode", "Deployment→WEC"},
+    },
+}
+
+func TestTSVRoundTrip(t *testing.T) {
+    root := t.TempDir()
+    if err := (&TSVSink{Root: root}).Write(awkward); err != nil {
+        t.Fatal(err)
+    }
+    path := filepath.Join(root, awkward.Path+".tsv")
+    data, err := os.ReadFile(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, line := range []string{
+        "backslash\tC:\\\\dir\\\\\n",
+        "tab\ta\\tb\n",
+        "newline\ta\\nb\n",
+        "carriage return\ta\\r\\nb\n",
+        "escape lookalike\ta\\\\tb\\\\n\n",
+    } {
+        if !strings.Contains(string(data), line) {
+            t.Errorf("%s lacks the escaped line %q:\n%s", path, line, data)
+        }
+    }
+    if lines := strings.Count(string(data), "\n"); lines != len(awkward.Rows)+2 {
+        t.Errorf("%s has %d lines, want one per row plus the schema line and header", path, lines)
+    }
+
+    got, err := ReadTSV(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !reflect.DeepEqual(got.Columns, awkward.Columns) || !reflect.DeepEqual(got.Rows, awkward.Rows) {
+        t.Errorf("ReadTSV = %q %q, want %q %q", got.Columns, got.Rows, awkward.Columns, awkward.Rows)
+    }
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+    root := t.TempDir()
+    if err := (&CSVSink{Root: root}).Write(awkward); err != nil {
+        t.Fatal(err)
+    }
+    path := filepath.Join(root, awkward.Path+".csv")
+    data, err := os.ReadFile(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    // RFC 4180: CRLF line ends, also inside values, the header first, and
+    // fields with quotes, commas or line breaks quoted with inner quotes
+    // doubled.
+    for _, line := range []string{
+        "Name,Value\r\n",
+        "quote,\"say \"\"hi\"\"\"\r\n",
+        "comma,\"a,b\"\r\n",
+        "newline,\"a\r\nb\"\r\n",
+        "empty,\r\n",
+    } {
+        if !strings.Contains(string(data), line) {
+            t.Errorf("%s lacks the line %q:\n%s", path, line, data)
+        }
+    }
+    if !strings.HasPrefix(string(data), "Name,Value\r\n") {
+        t.Errorf("%s does not start with its header:\n%s", path, data)
+    }
+
+    got, err := ReadCSV(root, path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    // encoding/csv reads a quoted \r\n as \n, as RFC 4180 readers may.
+    want := make([][]string, len(awkward.Rows))
+    for i, row := range awkward.Rows {
+        want[i] = []string{row[0], strings.ReplaceAll(row[1], "\r\n", "\n")}
+    }
+    if !reflect.DeepEqual(got.Columns, awkward.Columns) || !reflect.DeepEqual(got.Rows, want) {
+        t.Errorf("ReadCSV = %q %q, want %q %q", got.Columns, got.Rows, awkward.Columns, want)
+    }
+}
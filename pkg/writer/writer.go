@@ -1,67 +1,169 @@
 package writer
 
 import (
+    "encoding/json"
     "fmt"
     "os"
     "path/filepath"
+    "sort"
+    "strings"
+    "time"
 
     "github.com/asmit27rai/collector/pkg/collector"
 )
 
-func WriteMetrics(path, kind, context string, metrics []collector.ObjectMetrics) error {
-    dir := filepath.Join(path, kind+"-"+context)
-    if err := os.MkdirAll(dir, 0755); err != nil {
-        return err
-    }
+// Table is one stream of collected records laid out as named columns, for
+// example the deployments listed from the WDS in one namespace.
+type Table struct {
+    // Path locates the table below a sink's root, without file extension,
+    // e.g. perf-test-0/deployments-wds/deployments.
+    Path    string
+    Columns []string
+    Rows    [][]string
+}
 
-    file := filepath.Join(dir, kind+".csv")
-    f, err := os.Create(file)
-    if err != nil {
-        return err
-    }
-    defer f.Close()
+// Sink persists collected tables in one output format. Write may be called
+// concurrently for different tables.
+type Sink interface {
+    Write(t Table) error
+    Close() error
+}
 
-    // Write header
-    if _, err := f.WriteString("Name\tCreated\tStatusUpdate\tCondition\tManager\n"); err != nil {
-        return err
+// Formats lists the sink formats NewSink understands.
+var Formats = []string{"tsv", "csv", "json", "ndjson"}
+
+// NewSink returns a sink writing every table below root in each of the
+// given formats.
+func NewSink(root string, formats []string) (Sink, error) {
+    var sinks MultiSink
+    for _, format := range formats {
+        switch strings.TrimSpace(format) {
+        case "tsv":
+            sinks = append(sinks, &TSVSink{Root: root})
+        case "csv":
+            sinks = append(sinks, &CSVSink{Root: root})
+        case "json":
+            sinks = append(sinks, &JSONSink{Root: root})
+        case "ndjson":
+            sinks = append(sinks, &NDJSONSink{Root: root})
+        default:
+            return nil, fmt.Errorf("unknown output format %q (want one of %s)", format, strings.Join(Formats, ", "))
+        }
     }
+    return sinks, nil
+}
 
-    // Write data
-    for _, m := range metrics {
-        line := fmt.Sprintf("%s\t%s\t%s\t%s\t%s\n", 
-            m.Name, m.Created, m.StatusUpdate, m.Condition, m.Manager)
-        if _, err := f.WriteString(line); err != nil {
+// MultiSink fans every table out to several sinks.
+type MultiSink []Sink
+
+func (m MultiSink) Write(t Table) error {
+    for _, s := range m {
+        if err := s.Write(t); err != nil {
             return err
         }
     }
     return nil
 }
 
-func WriteWorkMetrics(path, kind string, metrics []collector.WorkMetrics) error {
-    dir := filepath.Join(path, kind)
-    if err := os.MkdirAll(dir, 0755); err != nil {
-        return err
+func (m MultiSink) Close() error {
+    var firstErr error
+    for _, s := range m {
+        if err := s.Close(); err != nil && firstErr == nil {
+            firstErr = err
+        }
     }
+    return firstErr
+}
 
-    file := filepath.Join(dir, kind+".csv")
-    f, err := os.Create(file)
-    if err != nil {
-        return err
+// ObjectTable lays out standard objects of kind listed from the cluster
+// playing role.
+func ObjectTable(nsPath, kind string, role collector.Role, metrics []collector.ObjectMetrics) Table {
+    t := Table{
+        Path:    filepath.Join(nsPath, kind+"-"+string(role), kind),
+        Columns: []string{"Cluster", "Kind", "Namespace", "Name", "UID", "Created", "StatusUpdate", "Condition", "Manager", "Stages"},
     }
-    defer f.Close()
-
-    // Write header
-    if _, err := f.WriteString("Name\tCreated\tUpdated\tStatus\tTargetObject\n"); err != nil {
-        return err
+    for _, m := range metrics {
+        t.Rows = append(t.Rows, []string{
+            m.Cluster, m.Kind, m.Namespace, m.Name, m.UID,
+            formatTime(&m.Created), formatTime(m.StatusUpdate), m.Condition, m.Manager, formatStages(m.Stages),
+        })
     }
+    return t
+}
 
-    // Write data
+// WorkTable lays out KubeStellar/OCM work objects of kind.
+func WorkTable(nsPath, kind string, metrics []collector.WorkMetrics) Table {
+    t := Table{
+        Path:    filepath.Join(nsPath, kind, kind),
+        Columns: []string{"Cluster", "Kind", "Namespace", "Name", "UID", "Created", "Updated", "Status", "TargetObject", "Stages"},
+    }
     for _, m := range metrics {
-        line := fmt.Sprintf("%s\t%s\t%s\t%s\t%s\n", 
-            m.Name, m.Created, m.Updated, m.Status, m.TargetObject)
-        if _, err := f.WriteString(line); err != nil {
-            return err
+        t.Rows = append(t.Rows, []string{
+            m.Cluster, m.Kind, m.Namespace, m.Name, m.UID,
+            formatTime(&m.Created), formatTime(m.Updated), m.Status, m.TargetObject, formatStages(m.Stages),
+        })
+    }
+    return t
+}
+
+// formatTime renders t without losing precision; unknown times are empty.
+func formatTime(t *time.Time) string {
+    if t == nil || t.IsZero() {
+        return ""
+    }
+    return t.UTC().Format(time.RFC3339Nano)
+}
+
+// formatStages renders a stage map as name=time pairs separated by ";",
+// sorted by name.
+func formatStages(stages map[string]time.Time) string {
+    names := make([]string, 0, len(stages))
+    for name := range stages {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    pairs := make([]string, 0, len(names))
+    for _, name := range names {
+        t := stages[name]
+        pairs = append(pairs, name+"="+formatTime(&t))
+    }
+    return strings.Join(pairs, ";")
+}
+
+// Column returns the index of the named column, or -1 if the table has none.
+func (t Table) Column(name string) int {
+    for i, col := range t.Columns {
+        if col == name {
+            return i
         }
     }
-    return nil
-}
\ No newline at end of file
+    return -1
+}
+
+// create opens root/path+ext for writing, creating parent directories.
+func create(root, path, ext string) (*os.File, error) {
+    file := filepath.Join(root, path+ext)
+    if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+        return nil, err
+    }
+    return os.Create(file)
+}
+
+// WriteErrors records every failed collection task in errors.json under path.
+// An empty list is written too, so a clean run is distinguishable from one
+// that never got this far.
+func WriteErrors(path string, errs []collector.CollectionError) error {
+    if err := os.MkdirAll(path, 0755); err != nil {
+        return err
+    }
+    if errs == nil {
+        errs = []collector.CollectionError{}
+    }
+
+    data, err := json.MarshalIndent(errs, "", "  ")
+    if err != nil {
+        return err
+    }
+    return os.WriteFile(filepath.Join(path, "errors.json"), append(data, '\n'), 0644)
+}